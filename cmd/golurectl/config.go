@@ -0,0 +1,301 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/robotomize/go-allure/internal/redact"
+	"github.com/robotomize/go-allure/internal/rules"
+)
+
+const (
+	configFileName = ".golurectl.yaml"
+	envPrefix      = "GOLURECTL_"
+)
+
+// secretFlags are masked by config print.
+var secretFlags = map[string]bool{"upload-token": true}
+
+var (
+	configFlag string
+	configPath string
+
+	// configLabelRules are the label rules written inline in the config file.
+	configLabelRules []rules.Rule
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Long:  "Inspect golurectl configuration",
+	Short: "golurectl configuration",
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:          "print",
+	Long:         "Print the effective configuration merged from flags, GOLURECTL_* environment variables and the config file",
+	Short:        "print the effective configuration",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configPath != "" {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "# %s\n", configPath)
+		}
+
+		config := effectiveConfig(cmd.Flags())
+		if len(configLabelRules) > 0 && labelRulesFlag == "" {
+			config["label-rules"] = configLabelRules
+		}
+
+		b, err := yaml.Marshal(config)
+		if err != nil {
+			return fmt.Errorf("yaml.Marshal: %w", err)
+		}
+
+		if _, err = cmd.OutOrStdout().Write(b); err != nil {
+			return fmt.Errorf("write config: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(
+		&configFlag,
+		"config",
+		"",
+		"",
+		"path to the config file, "+configFileName+" is searched from the working directory upwards by default",
+	)
+
+	configCmd.AddCommand(configPrintCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// applyConfig sets flags that are not given on the command line from GOLURECTL_* environment variables
+// and then from the config file.
+func applyConfig(cmd *cobra.Command, _ []string) error {
+	pth := configFlag
+	if pth == "" {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("os.Getwd: %w", err)
+		}
+
+		if pth, err = findConfig(pwd); err != nil {
+			return fmt.Errorf("find config: %w", err)
+		}
+	}
+
+	flags := cmd.Flags()
+	known := commandFlags(cmd.Root())
+
+	values := make(map[string]any)
+	if pth != "" {
+		var err error
+		if values, err = loadConfig(pth, known); err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+
+		configPath = pth
+	}
+
+	// Label rules are either a file name or a list of rules.
+	if value, ok := values["label-rules"].([]any); ok {
+		delete(values, "label-rules")
+		if err := decodeConfig(value, &configLabelRules); err != nil {
+			return fmt.Errorf("%s: label-rules: %w", pth, err)
+		}
+	}
+
+	// Options of other commands, such as addr of serve, are only applied when that command runs.
+	var errs []error
+	for name := range values {
+		if !known[name] || name == "config" || name == "help" {
+			errs = append(errs, fmt.Errorf("unknown config option %q", name))
+		}
+	}
+
+	flags.VisitAll(
+		func(flag *pflag.Flag) {
+			if flag.Changed || flag.Name == "config" || flag.Name == "help" {
+				return
+			}
+
+			if value, ok := os.LookupEnv(envName(flag.Name)); ok {
+				if err := flags.Set(flag.Name, value); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", envName(flag.Name), err))
+				}
+
+				return
+			}
+
+			if value, ok := values[flag.Name]; ok {
+				if err := setFlag(flags, flag, value); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %s: %w", pth, flag.Name, err))
+				}
+			}
+		},
+	)
+
+	return errors.Join(errs...)
+}
+
+// findConfig searches the config file from dir upwards and returns an empty path if there is none.
+func findConfig(dir string) (string, error) {
+	for {
+		pth := filepath.Join(dir, configFileName)
+		if _, err := os.Stat(pth); err == nil {
+			return pth, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("os.Stat: %w", err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+
+		dir = parent
+	}
+}
+
+// commandFlags returns the names of the flags of the command and all its subcommands.
+func commandFlags(cmd *cobra.Command) map[string]bool {
+	names := make(map[string]bool)
+	visit := func(flag *pflag.Flag) {
+		names[flag.Name] = true
+	}
+
+	cmd.Flags().VisitAll(visit)
+	cmd.PersistentFlags().VisitAll(visit)
+	for _, sub := range cmd.Commands() {
+		for name := range commandFlags(sub) {
+			names[name] = true
+		}
+	}
+
+	return names
+}
+
+// loadConfig reads the config file into flag names. Nested sections are joined with "-",
+// so upload.url sets --upload-url.
+func loadConfig(pth string, known map[string]bool) (map[string]any, error) {
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	var doc map[string]any
+	if err = yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+
+	values := make(map[string]any)
+	flattenConfig(known, "", doc, values)
+
+	return values, nil
+}
+
+func flattenConfig(known map[string]bool, prefix string, doc, values map[string]any) {
+	for key, value := range doc {
+		name := key
+		if prefix != "" {
+			name = prefix + "-" + key
+		}
+
+		// A section is a map that is not a value of a flag, such as allure-labels.
+		if section, ok := value.(map[string]any); ok && !known[name] {
+			flattenConfig(known, name, section, values)
+			continue
+		}
+
+		values[name] = value
+	}
+}
+
+// decodeConfig decodes a config value into a typed structure.
+func decodeConfig(value, dst any) error {
+	b, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("yaml.Marshal: %w", err)
+	}
+
+	if err = yaml.Unmarshal(b, dst); err != nil {
+		return fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+
+	return nil
+}
+
+// setFlag sets a config value, lists fill slice flags or are joined with commas and maps become key:value pairs.
+func setFlag(flags *pflag.FlagSet, flag *pflag.Flag, value any) error {
+	var items []string
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+	case map[string]any:
+		for key, item := range v {
+			items = append(items, fmt.Sprintf("%s:%v", key, item))
+		}
+
+		sort.Strings(items)
+	case nil:
+		return nil
+	default:
+		items = []string{fmt.Sprint(v)}
+	}
+
+	if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+		return sliceValue.Replace(items)
+	}
+
+	return flags.Set(flag.Name, strings.Join(items, ","))
+}
+
+// effectiveConfig returns the flag values typed as they are written in the config file.
+func effectiveConfig(flags *pflag.FlagSet) map[string]any {
+	config := make(map[string]any)
+	flags.VisitAll(
+		func(flag *pflag.Flag) {
+			if flag.Name == "config" || flag.Name == "help" {
+				return
+			}
+
+			value := flag.Value.String()
+			switch {
+			case secretFlags[flag.Name] && value != "":
+				config[flag.Name] = redact.Mask
+			case flag.Value.Type() == "bool":
+				config[flag.Name], _ = strconv.ParseBool(value)
+			case flag.Value.Type() == "int":
+				config[flag.Name], _ = strconv.Atoi(value)
+			default:
+				if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+					config[flag.Name] = sliceValue.GetSlice()
+					return
+				}
+
+				config[flag.Name] = value
+			}
+		},
+	)
+
+	return config
+}
+
+// envName returns the environment variable overriding the flag: upload-token is GOLURECTL_UPLOAD_TOKEN.
+func envName(flag string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
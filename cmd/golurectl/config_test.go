@@ -0,0 +1,287 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestEnvName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		flag     string
+		expected string
+	}{
+		{name: "test_single_word", flag: "silent", expected: "GOLURECTL_SILENT"},
+		{name: "test_dashes", flag: "upload-token", expected: "GOLURECTL_UPLOAD_TOKEN"},
+		{name: "test_many_dashes", flag: "attachment-max-size", expected: "GOLURECTL_ATTACHMENT_MAX_SIZE"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				if diff := cmp.Diff(tc.expected, envName(tc.flag)); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+func TestFlattenConfig(t *testing.T) {
+	t.Parallel()
+
+	known := map[string]bool{"upload-url": true, "upload-retries": true, "allure-labels": true, "silent": true}
+	doc := map[string]any{
+		"silent": true,
+		"upload": map[string]any{
+			"url":     "http://localhost:5050",
+			"retries": 2,
+		},
+		"allure-labels": map[string]any{"epic": "core"},
+		"unknown":       map[string]any{"option": 1},
+	}
+
+	expected := map[string]any{
+		"silent":         true,
+		"upload-url":     "http://localhost:5050",
+		"upload-retries": 2,
+		"allure-labels":  map[string]any{"epic": "core"},
+		"unknown-option": 1,
+	}
+
+	values := make(map[string]any)
+	flattenConfig(known, "", doc, values)
+
+	if diff := cmp.Diff(expected, values); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSetFlag(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		flag     func(flags *pflag.FlagSet)
+		value    any
+		expected string
+		err      bool
+	}{
+		{
+			name:     "test_string",
+			flag:     func(flags *pflag.FlagSet) { flags.String("value", "", "") },
+			value:    "allure-results",
+			expected: "allure-results",
+		},
+		{
+			name:     "test_int",
+			flag:     func(flags *pflag.FlagSet) { flags.Int("value", 0, "") },
+			value:    3,
+			expected: "3",
+		},
+		{
+			name:     "test_bool",
+			flag:     func(flags *pflag.FlagSet) { flags.Bool("value", false, "") },
+			value:    true,
+			expected: "true",
+		},
+		{
+			name:     "test_list_into_slice",
+			flag:     func(flags *pflag.FlagSet) { flags.StringSlice("value", []string{"default"}, "") },
+			value:    []any{"a", "b"},
+			expected: "[a,b]",
+		},
+		{
+			name:     "test_list_into_string",
+			flag:     func(flags *pflag.FlagSet) { flags.String("value", "", "") },
+			value:    []any{"integration", "slow"},
+			expected: "integration,slow",
+		},
+		{
+			name:     "test_map_into_slice",
+			flag:     func(flags *pflag.FlagSet) { flags.StringSlice("value", nil, "") },
+			value:    map[string]any{"story": "export", "epic": "core"},
+			expected: "[epic:core,story:export]",
+		},
+		{
+			name:     "test_nil",
+			flag:     func(flags *pflag.FlagSet) { flags.String("value", "default", "") },
+			value:    nil,
+			expected: "default",
+		},
+		{
+			name:  "test_invalid_int",
+			flag:  func(flags *pflag.FlagSet) { flags.Int("value", 0, "") },
+			value: "three",
+			err:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+				tc.flag(flags)
+
+				flag := flags.Lookup("value")
+				err := setFlag(flags, flag, tc.value)
+				if (err != nil) != tc.err {
+					t.Fatalf("got: %v, want error: %v", err, tc.err)
+				}
+
+				if tc.err {
+					return
+				}
+
+				if diff := cmp.Diff(tc.expected, flag.Value.String()); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+// TestApplyConfig - tests that flags win over GOLURECTL_* variables and variables win over the config file.
+// It changes the environment and the package config variables, so it does not run in parallel.
+func TestApplyConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   string
+		env      map[string]string
+		args     []string
+		expected map[string]string
+		err      bool
+	}{
+		{
+			name:     "test_config_file",
+			config:   "output: from-file\nupload:\n  retries: 5\n",
+			args:     []string{},
+			expected: map[string]string{"output": "from-file", "upload-retries": "5", "addr": "localhost:8080"},
+		},
+		{
+			name:     "test_env_over_file",
+			config:   "output: from-file\n",
+			env:      map[string]string{"GOLURECTL_OUTPUT": "from-env"},
+			args:     []string{},
+			expected: map[string]string{"output": "from-env", "upload-retries": "3", "addr": "localhost:8080"},
+		},
+		{
+			name:     "test_flag_over_env",
+			config:   "output: from-file\nupload:\n  retries: 5\n",
+			env:      map[string]string{"GOLURECTL_OUTPUT": "from-env", "GOLURECTL_UPLOAD_RETRIES": "0"},
+			args:     []string{"--output", "from-flag"},
+			expected: map[string]string{"output": "from-flag", "upload-retries": "0", "addr": "localhost:8080"},
+		},
+		{
+			name:     "test_subcommand_option_on_root",
+			config:   "output: from-file\naddr: localhost:9090\n",
+			args:     []string{},
+			expected: map[string]string{"output": "from-file", "upload-retries": "3", "addr": "localhost:8080"},
+		},
+		{
+			name:     "test_subcommand_option",
+			config:   "output: from-file\naddr: localhost:9090\n",
+			args:     []string{"serve"},
+			expected: map[string]string{"output": "from-file", "upload-retries": "3", "addr": "localhost:9090"},
+		},
+		{
+			name:   "test_unknown_option",
+			config: "outptu: from-file\n",
+			args:   []string{},
+			err:    true,
+		},
+		{
+			name:     "test_empty_config",
+			args:     []string{},
+			expected: map[string]string{"output": "", "upload-retries": "3", "addr": "localhost:8080"},
+		},
+		{
+			name: "test_invalid_env",
+			env:  map[string]string{"GOLURECTL_UPLOAD_RETRIES": "three"},
+			args: []string{},
+			err:  true,
+		},
+		{
+			name:   "test_invalid_value",
+			config: "upload:\n  retries: three\n",
+			args:   []string{},
+			err:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				for key, value := range tc.env {
+					t.Setenv(key, value)
+				}
+
+				// An explicit config file keeps the config of the working tree out of the test.
+				pth := filepath.Join(t.TempDir(), configFileName)
+				if err := os.WriteFile(pth, []byte(tc.config), 0o644); err != nil {
+					t.Fatalf("os.WriteFile: %v", err)
+				}
+
+				values := make(map[string]string)
+				root := testConfigCommands(values)
+				root.SetArgs(append(tc.args, "--config", pth))
+
+				err := root.Execute()
+				if (err != nil) != tc.err {
+					t.Fatalf("got: %v, want error: %v", err, tc.err)
+				}
+
+				if tc.err {
+					return
+				}
+
+				if diff := cmp.Diff(tc.expected, values); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+// testConfigCommands builds a root command with a serve subcommand, both record the flag values they run with.
+func testConfigCommands(values map[string]string) *cobra.Command {
+	var output, addr string
+	var retries int
+
+	record := func(cmd *cobra.Command, _ []string) {
+		values["output"] = output
+		values["upload-retries"] = cmd.Flags().Lookup("upload-retries").Value.String()
+		values["addr"] = addr
+	}
+
+	root := &cobra.Command{
+		Use:               "golurectl",
+		SilenceUsage:      true,
+		SilenceErrors:     true,
+		PersistentPreRunE: applyConfig,
+		Run:               record,
+	}
+	root.PersistentFlags().StringVar(&configFlag, "config", "", "")
+	root.PersistentFlags().StringVarP(&output, "output", "o", "", "")
+	root.PersistentFlags().IntVar(&retries, "upload-retries", 3, "")
+
+	serve := &cobra.Command{Use: "serve", Run: record}
+	serve.Flags().StringVar(&addr, "addr", "localhost:8080", "")
+	root.AddCommand(serve)
+
+	return root
+}
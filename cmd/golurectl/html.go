@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robotomize/go-allure/internal/exporter"
+)
+
+var htmlCmd = &cobra.Command{
+	Use:          "html <results-dir>",
+	Long:         "Render an allure results directory into a self-contained HTML report",
+	Short:        "html report of allure results",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		report, err := exporter.ReadReport(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("exporter.ReadReport: %w", err)
+		}
+
+		if htmlOutputFlag == "" {
+			if err = exporter.WriteHTML(cmd.OutOrStdout(), report); err != nil {
+				return fmt.Errorf("exporter.WriteHTML: %w", err)
+			}
+
+			return nil
+		}
+
+		writer := exporter.NewWriter(exporter.WriteHTMLTo(htmlOutputFlag))
+		if err = writer.WriteReport(ctx, report.Tests); err != nil {
+			return fmt.Errorf("exporter.NewWriter WriteReport: %w", err)
+		}
+
+		if err = writer.WriteAttachments(ctx, report.Attachments); err != nil {
+			return fmt.Errorf("exporter.NewWriter WriteAttachments: %w", err)
+		}
+
+		if err = writer.Flush(ctx); err != nil {
+			return fmt.Errorf("exporter.NewWriter Flush: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(htmlCmd)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robotomize/go-allure/internal/merge"
+)
+
+var mergeCmd = &cobra.Command{
+	Use:          "merge <results-dir>...",
+	Long:         "Merge several allure results directories into the output directory",
+	Short:        "merge allure results directories",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if outputDirFlag == "" {
+			return errors.New("output path is required: -o <report-path>")
+		}
+
+		result, err := merge.Merge(cmd.Context(), outputDirFlag, args...)
+		if err != nil {
+			return fmt.Errorf("merge.Merge: %w", err)
+		}
+
+		_, _ = fmt.Fprintf(
+			cmd.OutOrStdout(), "Merged %d results, %d duplicates skipped, %d retries, %d attachments\n",
+			result.Tests, result.Duplicates, result.Retries, result.Attachments,
+		)
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robotomize/go-allure/internal/parser"
+)
+
+var collectMetadataFileFlag string
+
+var collectMetadataCmd = &cobra.Command{
+	Use:          "collect-metadata [import-paths...]",
+	Long:         "Collect test metadata of the working directory into a JSON file, which is read with --metadata where the sources or Go are not available",
+	Short:        "collect test metadata for --metadata",
+	Args:         importPathArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("os.Getwd: %w", err)
+		}
+
+		fileParser, err := newParser(pwd)
+		if err != nil {
+			return err
+		}
+
+		methods, err := fileParser.ParseFiles(cmd.Context(), args...)
+		if err != nil {
+			return fmt.Errorf("parser ParseFiles: %w", err)
+		}
+
+		file, err := os.Create(collectMetadataFileFlag)
+		if err != nil {
+			return fmt.Errorf("os.Create: %w", err)
+		}
+
+		defer file.Close()
+
+		if err = parser.WriteMetadata(file, methods); err != nil {
+			return fmt.Errorf("parser.WriteMetadata: %w", err)
+		}
+
+		if err = file.Close(); err != nil {
+			return fmt.Errorf("file Close: %w", err)
+		}
+
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Collected %d tests into %s\n", len(methods), collectMetadataFileFlag)
+
+		return nil
+	},
+}
+
+func init() {
+	collectMetadataCmd.Flags().StringVarP(
+		&collectMetadataFileFlag,
+		"file",
+		"f",
+		"metadata.json",
+		"path to the written metadata file: -f metadata.json",
+	)
+
+	rootCmd.AddCommand(collectMetadataCmd)
+}
+
+// importPathArgs rejects package patterns and directories, the packages are selected by import paths only.
+func importPathArgs(_ *cobra.Command, args []string) error {
+	for _, arg := range args {
+		if strings.Contains(arg, "...") || strings.HasPrefix(arg, ".") || filepath.IsAbs(arg) {
+			return fmt.Errorf(
+				"%q is not an import path: pass import paths or nothing to collect the metadata of all packages", arg,
+			)
+		}
+	}
+
+	return nil
+}
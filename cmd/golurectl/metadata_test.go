@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestImportPathArgs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		args []string
+		err  bool
+	}{
+		{name: "test_no_args", args: nil},
+		{name: "test_import_paths", args: []string{"github.com/robotomize/go-allure/internal/parser", "fmt"}},
+		{name: "test_pattern", args: []string{"./..."}, err: true},
+		{name: "test_import_path_pattern", args: []string{"github.com/robotomize/go-allure/..."}, err: true},
+		{name: "test_relative_dir", args: []string{"./internal/parser"}, err: true},
+		{name: "test_absolute_dir", args: []string{"/src/go-allure"}, err: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				if err := importPathArgs(collectMetadataCmd, tc.args); (err != nil) != tc.err {
+					t.Errorf("got: %v, want error: %v", err, tc.err)
+				}
+			},
+		)
+	}
+}
@@ -1,19 +1,30 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/robotomize/go-allure/internal/fs"
 	"github.com/spf13/cobra"
 
 	"github.com/robotomize/go-allure/internal/allure"
+	"github.com/robotomize/go-allure/internal/cache"
+	"github.com/robotomize/go-allure/internal/codeowners"
 	"github.com/robotomize/go-allure/internal/exporter"
+	"github.com/robotomize/go-allure/internal/git"
 	"github.com/robotomize/go-allure/internal/golist"
 	"github.com/robotomize/go-allure/internal/gotest"
+	"github.com/robotomize/go-allure/internal/junit"
 	"github.com/robotomize/go-allure/internal/parser"
+	"github.com/robotomize/go-allure/internal/redact"
+	"github.com/robotomize/go-allure/internal/rules"
 	"github.com/robotomize/go-allure/internal/slice"
 )
 
@@ -23,12 +34,50 @@ var (
 	forwardGoTestExitCode bool
 	forwardGoTestLog      bool
 	goBuildTagsFlag       string
+	goFlagsFlag           string
+	cacheFlag             bool
+	cacheDirFlag          string
+	metadataFlag          string
+	includeFlag           string
+	excludeFlag           string
 	allureSuiteFlag       string
 	allureTagsFlag        string
 	allureLayersFlag      string
 	allureLabelsFlag      string
 	allureAttachmentForce bool
 	silentOutput          bool
+	inputFormatFlag       string
+	junitOutputFlag       string
+	ctrfOutputFlag        string
+	summaryMarkdownFlag   string
+	htmlOutputFlag        string
+	uploadURLFlag         string
+	uploadTokenFlag       string
+	uploadProjectFlag     string
+	uploadBatchSizeFlag   int
+	uploadRetriesFlag     int
+	uploadDryRunFlag      bool
+	outputArchiveFlag     string
+	fsyncFlag             bool
+	writeWorkersFlag      int
+	cleanOutputFlag       bool
+	outputRunSubdirFlag   bool
+	attachmentMaxSize     int
+	attachmentTotalSize   int
+	redactFlag            bool
+	redactPatternsFlag    []string
+	redactEnvFlag         string
+	labelRulesFlag        string
+	codeOwnersFlag        bool
+	gitMetadataFlag       bool
+	sourceLinkFlag        string
+	attachSourceFlag      bool
+	failureSourceFlag     bool
+)
+
+const (
+	inputFormatGoTest = "gotest"
+	inputFormatJUnit  = "junit"
 )
 
 func init() {
@@ -67,6 +116,48 @@ func init() {
 		"",
 		"pass custom build tags: --gotags integration,fixture,linux",
 	)
+	rootCmd.PersistentFlags().StringVarP(
+		&goFlagsFlag,
+		"goflags",
+		"",
+		"",
+		"pass flags to go list in addition to GOFLAGS: --goflags '-mod=vendor'",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&cacheFlag,
+		"cache",
+		"",
+		true,
+		"cache go list metadata and parsed test files between runs",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&cacheDirFlag,
+		"cache-dir",
+		"",
+		"",
+		"cache directory, golurectl in the user cache directory by default",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&metadataFlag,
+		"metadata",
+		"",
+		"",
+		"read test metadata written by collect-metadata instead of running go list: --metadata metadata.json",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&includeFlag,
+		"include",
+		"",
+		"",
+		"load only packages under the matching directories: --include 'services/**,internal'",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&excludeFlag,
+		"exclude",
+		"",
+		"",
+		"skip packages under the matching directories: --exclude 'internal/legacy,**/e2e'",
+	)
 	rootCmd.PersistentFlags().StringVarP(
 		&allureSuiteFlag,
 		"allure-suite",
@@ -95,6 +186,48 @@ func init() {
 		"",
 		"add allure custom labels to all tests: --allure-labels key:value,key:value1,key1:value",
 	)
+	rootCmd.PersistentFlags().StringVarP(
+		&labelRulesFlag,
+		"label-rules",
+		"",
+		"",
+		"YAML file with rules labelling tests by package, file or test name: --label-rules labels.yaml",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&codeOwnersFlag,
+		"codeowners",
+		"",
+		false,
+		"add owner labels from the CODEOWNERS file of the repository",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&gitMetadataFlag,
+		"git",
+		"",
+		false,
+		"add git commit, branch, repository URL and test author labels and environment entries",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&sourceLinkFlag,
+		"source-link-template",
+		"",
+		"",
+		"add a link to the test source: --source-link-template 'https://github.com/org/repo/blob/{sha}/{file}#L{line}'",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&attachSourceFlag,
+		"attach-source",
+		"",
+		false,
+		"attach the source code of the test function",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&failureSourceFlag,
+		"failure-source",
+		"",
+		false,
+		"attach source excerpts of the file:line locations in failure logs and link them with --source-link-template",
+	)
 	rootCmd.PersistentFlags().BoolVarP(
 		&allureAttachmentForce,
 		"attachment-force",
@@ -109,6 +242,153 @@ func init() {
 		false,
 		"silent allure report output(JSON)",
 	)
+	rootCmd.PersistentFlags().StringVarP(
+		&inputFormatFlag,
+		"input-format",
+		"",
+		inputFormatGoTest,
+		"format of the input read from stdin: --input-format gotest|junit",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&junitOutputFlag,
+		"junit-output",
+		"",
+		"",
+		"write JUnit XML report to the given path: --junit-output junit.xml",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&ctrfOutputFlag,
+		"ctrf-output",
+		"",
+		"",
+		"write CTRF JSON report to the given path: --ctrf-output ctrf-report.json",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&summaryMarkdownFlag,
+		"summary-md",
+		"",
+		"",
+		"append Markdown summary to the given path: --summary-md $GITHUB_STEP_SUMMARY",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&htmlOutputFlag,
+		"html-output",
+		"",
+		"",
+		"write self-contained HTML report to the given path: --html-output report.html",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&uploadURLFlag,
+		"upload-url",
+		"",
+		"",
+		"upload results to the allure-docker-service send-results endpoint: --upload-url http://localhost:5050/allure-docker-service/send-results",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&uploadTokenFlag,
+		"upload-token",
+		"",
+		"",
+		"bearer token for the allure server upload",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&uploadProjectFlag,
+		"upload-project",
+		"",
+		"",
+		"allure server project id: --upload-project default",
+	)
+	rootCmd.PersistentFlags().IntVarP(
+		&uploadBatchSizeFlag,
+		"upload-batch-size",
+		"",
+		100,
+		"number of files sent to the allure server in a single request",
+	)
+	rootCmd.PersistentFlags().IntVarP(
+		&uploadRetriesFlag,
+		"upload-retries",
+		"",
+		3,
+		"number of retries of a failed upload request, 0 disables retries",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&uploadDryRunFlag,
+		"upload-dry-run",
+		"",
+		false,
+		"print the upload requests instead of sending them",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&outputArchiveFlag,
+		"output-archive",
+		"",
+		"",
+		"write allure reports into a tar.gz or zip archive: --output-archive report.tar.gz",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&fsyncFlag,
+		"fsync",
+		"",
+		false,
+		"sync every written report file to disk",
+	)
+	rootCmd.PersistentFlags().IntVarP(
+		&writeWorkersFlag,
+		"write-workers",
+		"",
+		runtime.NumCPU(),
+		"number of report files written concurrently",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&cleanOutputFlag,
+		"clean",
+		"",
+		false,
+		"remove allure reports and run subdirectories of previous runs from the output path",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&outputRunSubdirFlag,
+		"output-run-subdir",
+		"",
+		false,
+		"write allure reports into a timestamped subdirectory of the output path",
+	)
+	rootCmd.PersistentFlags().IntVarP(
+		&attachmentMaxSize,
+		"attachment-max-size",
+		"",
+		0,
+		"maximum size of a single attachment in bytes, the middle of longer logs is truncated",
+	)
+	rootCmd.PersistentFlags().IntVarP(
+		&attachmentTotalSize,
+		"attachment-total-size",
+		"",
+		0,
+		"maximum size of all attachments in bytes",
+	)
+	rootCmd.PersistentFlags().BoolVarP(
+		&redactFlag,
+		"redact",
+		"",
+		true,
+		"mask AWS keys, bearer tokens, passwords in URLs and JWTs in logs and attachments",
+	)
+	rootCmd.PersistentFlags().StringArrayVarP(
+		&redactPatternsFlag,
+		"redact-pattern",
+		"",
+		nil,
+		"mask matches of the regexp, only the (?P<secret>...) group if present: --redact-pattern 'api_key=(?P<secret>\\w+)'",
+	)
+	rootCmd.PersistentFlags().StringVarP(
+		&redactEnvFlag,
+		"redact-env",
+		"",
+		"",
+		"mask values of the environment variables, * matches any characters: --redact-env DB_PASSWORD,*_TOKEN",
+	)
 }
 
 // Declare the root command for the CLI tool.
@@ -116,11 +396,34 @@ var rootCmd = &cobra.Command{
 	Use:          "golurectl",
 	Long:         "Export go test output to allure reports",
 	SilenceUsage: true,
+	// Flags not given on the command line are read from the environment and the config file.
+	PersistentPreRunE: applyConfig,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 
 		opts := []exporter.Option{
 			exporter.WithAllureLabels(processAllureLabels()...),
+			exporter.WithAttachmentLimits(attachmentMaxSize, attachmentTotalSize),
+		}
+
+		// Add labels by rules
+		labelRules, err := loadLabelRules()
+		if err != nil {
+			return fmt.Errorf("label rules: %w", err)
+		}
+
+		if len(labelRules) > 0 {
+			opts = append(opts, exporter.WithLabelRules(labelRules))
+		}
+
+		// Add option to mask secrets
+		redactor, err := newRedactor()
+		if err != nil {
+			return fmt.Errorf("redact: %w", err)
+		}
+
+		if redactor != nil {
+			opts = append(opts, exporter.WithRedactor(redactor))
 		}
 
 		// Add option to force attachment
@@ -134,20 +437,59 @@ var rootCmd = &cobra.Command{
 			return fmt.Errorf("os.Getwd: %w", err)
 		}
 
-		// Add go build tags if provided
-		var buildArgs []string
-		if goBuildTagsFlag != "" {
-			buildArgs = append([]string{"-tags"}, strings.Split(strings.TrimSpace(goBuildTagsFlag), ",")...)
+		// Add git metadata
+		if gitMetadataFlag {
+			opts = append(opts, exporter.WithGitMetadata())
 		}
 
-		// Create the reader to read the go test output
-		pkgReader := gotest.NewReader(os.Stdin)
+		// Add links to the test sources
+		if sourceLinkFlag != "" {
+			opts = append(opts, exporter.WithSourceLinks(sourceLinks(ctx, pwd)))
+		}
 
-		// Create the parser using the go list retriver
-		goParser := parser.New(golist.NewRetriever(fs.New(pwd), buildArgs...))
+		if attachSourceFlag {
+			opts = append(opts, exporter.WithSourceAttachment())
+		}
+
+		if failureSourceFlag {
+			opts = append(opts, exporter.WithFailureSources())
+		}
+
+		// Add owner labels from the CODEOWNERS file
+		if codeOwnersFlag {
+			owners, err := codeowners.Load(repositoryRoot(pwd))
+			if err != nil {
+				return fmt.Errorf("codeowners.Load: %w", err)
+			}
+
+			if owners == nil && verboseFlag {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "CODEOWNERS file not found\n")
+			}
+
+			opts = append(opts, exporter.WithCodeOwners(owners))
+		}
+
+		// Create the reader to read the go test output or the JUnit XML report
+		var pkgReader exporter.Reader
+		switch inputFormatFlag {
+		case inputFormatGoTest:
+			pkgReader = gotest.NewReader(os.Stdin)
+		case inputFormatJUnit:
+			pkgReader = junit.NewReader(os.Stdin)
+		default:
+			return fmt.Errorf("unknown input format: %s", inputFormatFlag)
+		}
+
+		// Use the collected metadata or parse the test files of the working directory
+		var fileParser exporter.FileParser = parser.NewMetadataFile(metadataFlag)
+		if metadataFlag == "" {
+			if fileParser, err = newParser(pwd); err != nil {
+				return err
+			}
+		}
 
 		// Create the allure exporter with the options
-		allureExporter := exporter.New(goParser, pkgReader, opts...)
+		allureExporter := exporter.New(fileParser, pkgReader, opts...)
 
 		// Read the go test output and parse it into allure reports
 		if err := allureExporter.Read(ctx); err != nil {
@@ -165,6 +507,36 @@ var rootCmd = &cobra.Command{
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Read go test output log: %s", allureReport.Err.Error())
 		}
 
+		// Warn about the tests exported without metadata
+		if allureReport.MetadataErr != nil {
+			_, _ = fmt.Fprintf(
+				cmd.OutOrStdout(), "Warning: tests are exported without metadata: %s\n", allureReport.MetadataErr.Error(),
+			)
+		}
+
+		// Print what was cut by the attachment size limits
+		if len(allureReport.Truncated) > 0 {
+			var dropped int
+			for _, t := range allureReport.Truncated {
+				dropped += t.Size - t.Written
+				switch {
+				case !verboseFlag:
+				case t.Source == "":
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Dropped attachment %s: %d bytes over the total size\n", t.Name, t.Size)
+				default:
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Truncated attachment %s (%s): %d of %d bytes written\n", t.Name, t.Source, t.Written, t.Size)
+				}
+			}
+
+			_, _ = fmt.Fprintf(
+				cmd.OutOrStdout(), "Truncated %d attachments, %d bytes dropped\n", len(allureReport.Truncated), dropped,
+			)
+		}
+
+		if verboseFlag && allureReport.Deduplicated > 0 {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Deduplicated %d attachments\n", allureReport.Deduplicated)
+		}
+
 		// Copy go test output log if forwardGoTestLog flag is enabled
 		if forwardGoTestLog {
 			if _, err := io.Copy(cmd.OutOrStdout(), allureReport.OutputLog); err != nil {
@@ -173,9 +545,56 @@ var rootCmd = &cobra.Command{
 		}
 
 		// Set options for the exporter writer
-		var wOpts []exporter.WriterOption
-		if outputDirFlag != "" {
+		wOpts := []exporter.WriterOption{exporter.WithWorkers(writeWorkersFlag)}
+		switch {
+		case outputDirFlag != "" && outputRunSubdirFlag:
+			run := time.Now().Format(exporter.RunSubdirLayout)
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Write reports to %s\n", filepath.Join(outputDirFlag, run))
+			wOpts = append(wOpts, exporter.WriteToRunSubdir(outputDirFlag, run))
+		case outputDirFlag != "":
 			wOpts = append(wOpts, exporter.WriteToFile(outputDirFlag))
+		default:
+		}
+
+		if cleanOutputFlag {
+			wOpts = append(wOpts, exporter.WithClean())
+		}
+
+		if fsyncFlag {
+			wOpts = append(wOpts, exporter.WithFsync())
+		}
+
+		if outputArchiveFlag != "" {
+			wOpts = append(wOpts, exporter.WriteArchiveTo(outputArchiveFlag))
+		}
+
+		if junitOutputFlag != "" {
+			wOpts = append(wOpts, exporter.WriteJUnitTo(junitOutputFlag))
+		}
+
+		if ctrfOutputFlag != "" {
+			wOpts = append(wOpts, exporter.WriteCTRFTo(ctrfOutputFlag))
+		}
+
+		if htmlOutputFlag != "" {
+			wOpts = append(wOpts, exporter.WriteHTMLTo(htmlOutputFlag))
+		}
+
+		// The upload settings can be given as GOLURECTL_UPLOAD_* to keep tokens out of the command line.
+		if uploadURLFlag != "" {
+			wOpts = append(
+				wOpts, exporter.UploadTo(
+					exporter.Upload{
+						Endpoint:  uploadURLFlag,
+						Token:     uploadTokenFlag,
+						Project:   uploadProjectFlag,
+						BatchSize: uploadBatchSizeFlag,
+						Retries:   uploadRetriesFlag,
+						DryRun:    uploadDryRunFlag,
+						Log:       cmd.OutOrStdout(),
+					},
+				),
+			)
 		}
 
 		if !silentOutput {
@@ -191,14 +610,35 @@ var rootCmd = &cobra.Command{
 		}
 
 		// Write the attachments
-		if len(outputDirFlag) > 0 && len(allureReport.Attachments) > 0 {
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Write attachments\n")
+		if len(allureReport.Attachments) > 0 {
+			if len(outputDirFlag) > 0 || len(outputArchiveFlag) > 0 {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Write attachments\n")
+			}
 
 			if err := writer.WriteAttachments(ctx, allureReport.Attachments); err != nil {
 				return fmt.Errorf("exporter.NewWriter WriteAttachments: %w", err)
 			}
 		}
 
+		// Write the environment entries
+		if len(allureReport.Environment) > 0 {
+			if err := writer.WriteEnvironment(ctx, allureReport.Environment); err != nil {
+				return fmt.Errorf("exporter.NewWriter WriteEnvironment: %w", err)
+			}
+		}
+
+		// Write the aggregated reports
+		if err := writer.Flush(ctx); err != nil {
+			return fmt.Errorf("exporter.NewWriter Flush: %w", err)
+		}
+
+		// Write the Markdown summary
+		if summaryMarkdownFlag != "" {
+			if err := writeSummaryFile(summaryMarkdownFlag, allureReport); err != nil {
+				return fmt.Errorf("write summary: %w", err)
+			}
+		}
+
 		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Conversion completed successfully\n")
 
 		// Exit with error code 1 if one or more go tests failed
@@ -220,6 +660,141 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// newParser creates the test file parser using go list in the directory with the build tags, GOFLAGS and cache.
+func newParser(dir string) (*parser.Parser, error) {
+	// Add go build tags if provided
+	var buildArgs []string
+	if goBuildTagsFlag != "" {
+		buildArgs = append([]string{"-tags"}, strings.Split(strings.TrimSpace(goBuildTagsFlag), ",")...)
+	}
+
+	buildArgs = append(buildArgs, strings.Fields(goFlagsFlag)...)
+
+	// Create the parser using the go list retriver
+	var retriever golist.PackageRetriever = golist.NewRetriever(fs.New(dir), buildArgs...)
+	var parserOpts []parser.Option
+	if cacheFlag {
+		c, err := openCache()
+		if err != nil {
+			return nil, fmt.Errorf("open cache: %w", err)
+		}
+
+		retriever = golist.WithCache(retriever, c, dir, buildArgs...)
+		parserOpts = append(parserOpts, parser.WithCache(c))
+	}
+
+	if includeFlag != "" || excludeFlag != "" {
+		retriever = golist.WithPathFilter(retriever, dir, strings.Split(includeFlag, ","), strings.Split(excludeFlag, ","))
+	}
+
+	return parser.New(retriever, parserOpts...), nil
+}
+
+// openCache opens the cache in the --cache-dir or the default cache directory.
+func openCache() (*cache.Cache, error) {
+	dir := cacheDirFlag
+	if dir == "" {
+		var err error
+		if dir, err = cache.Dir(); err != nil {
+			return nil, fmt.Errorf("cache.Dir: %w", err)
+		}
+	}
+
+	return cache.New(dir), nil
+}
+
+// repositoryRoot returns the closest directory containing .git or dir itself.
+func repositoryRoot(dir string) string {
+	for root := dir; ; {
+		if _, err := os.Stat(filepath.Join(root, ".git")); err == nil {
+			return root
+		}
+
+		parent := filepath.Dir(root)
+		if parent == root {
+			return dir
+		}
+
+		root = parent
+	}
+}
+
+// sourceLinks resolves the repository root and the commit of the source links,
+// outside a git work tree the links point to HEAD.
+func sourceLinks(ctx context.Context, dir string) exporter.SourceLinks {
+	links := exporter.SourceLinks{Template: sourceLinkFlag, Root: repositoryRoot(dir), SHA: "HEAD"}
+	if repo, err := git.Open(ctx, dir); err == nil {
+		links.Root = repo.Root
+		if repo.Commit != "" {
+			links.SHA = repo.Commit
+		}
+	}
+
+	return links
+}
+
+// loadLabelRules compiles the label rules of the config file and the --label-rules file.
+func loadLabelRules() (rules.Rules, error) {
+	labelRules := configLabelRules
+	if labelRulesFlag != "" {
+		fileRules, err := rules.Load(labelRulesFlag)
+		if err != nil {
+			return nil, fmt.Errorf("rules.Load: %w", err)
+		}
+
+		labelRules = append(labelRules, fileRules...)
+	}
+
+	compiled, err := rules.Compile(labelRules...)
+	if err != nil {
+		return nil, fmt.Errorf("rules.Compile: %w", err)
+	}
+
+	return compiled, nil
+}
+
+// newRedactor creates the redactor from the redact flags or returns nil if there is nothing to mask.
+func newRedactor() (*redact.Redactor, error) {
+	var opts []redact.Option
+	if !redactFlag {
+		opts = append(opts, redact.WithoutBuiltins())
+	}
+
+	patterns, err := redact.Compile(redactPatternsFlag...)
+	if err != nil {
+		return nil, fmt.Errorf("redact.Compile: %w", err)
+	}
+
+	values := envValues(strings.Split(redactEnvFlag, ","))
+	if !redactFlag && len(patterns) == 0 && len(values) == 0 {
+		return nil, nil
+	}
+
+	opts = append(opts, redact.WithPatterns(patterns...), redact.WithValues(values...))
+
+	return redact.New(opts...), nil
+}
+
+// envValues returns values of the environment variables matching the names, which may contain * wildcards.
+func envValues(names []string) []string {
+	var values []string
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+
+			if ok, _ := path.Match(name, key); ok {
+				values = append(values, value)
+				break
+			}
+		}
+	}
+
+	return values
+}
+
 func processAllureLabels() []allure.Label {
 	var labels []allure.Label
 	if len(allureSuiteFlag) > 0 {
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robotomize/go-allure/internal/server"
+)
+
+var serveAddrFlag string
+
+var serveCmd = &cobra.Command{
+	Use:          "serve <results-dir>",
+	Long:         "Serve an allure results directory over HTTP with a lightweight viewer",
+	Short:        "serve allure results locally",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Serving %s on http://%s\n", args[0], serveAddrFlag)
+
+		if err := server.New(args[0]).ListenAndServe(ctx, serveAddrFlag); err != nil {
+			return fmt.Errorf("server ListenAndServe: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVarP(
+		&serveAddrFlag,
+		"addr",
+		"",
+		"localhost:8080",
+		"address to listen on: --addr localhost:8080",
+	)
+
+	rootCmd.AddCommand(serveCmd)
+}
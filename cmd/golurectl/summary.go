@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robotomize/go-allure/internal/exporter"
+)
+
+var summaryCmd = &cobra.Command{
+	Use:          "summary <results-dir>",
+	Long:         "Render a Markdown summary of an allure results directory",
+	Short:        "markdown summary of allure results",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := exporter.ReadReport(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("exporter.ReadReport: %w", err)
+		}
+
+		if summaryMarkdownFlag != "" {
+			return writeSummaryFile(summaryMarkdownFlag, report)
+		}
+
+		if err = exporter.WriteSummary(cmd.OutOrStdout(), report); err != nil {
+			return fmt.Errorf("exporter.WriteSummary: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(summaryCmd)
+}
+
+// writeSummaryFile appends the Markdown summary to the file, as $GITHUB_STEP_SUMMARY expects.
+func writeSummaryFile(pth string, report exporter.Report) error {
+	file, err := os.OpenFile(pth, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile: %w", err)
+	}
+
+	defer file.Close()
+
+	if err = exporter.WriteSummary(file, report); err != nil {
+		return fmt.Errorf("exporter.WriteSummary: %w", err)
+	}
+
+	return nil
+}
@@ -10,31 +10,45 @@ const (
 )
 
 type Test struct {
-	UUID        string       `json:"uuid"`
-	TestCaseID  string       `json:"testCaseId"`
-	HistoryID   string       `json:"historyId"`
-	Name        string       `json:"name"`
-	Description string       `json:"description"`
-	Status      string       `json:"status"`
-	Stage       string       `json:"stage"`
-	Steps       []Step       `json:"steps"`
-	Start       int64        `json:"start"`
-	Stop        int64        `json:"stop"`
-	FullName    string       `json:"fullName"`
-	Parameters  []Parameter  `json:"parameters"`
-	Labels      []Label      `json:"labels"`
-	Attachments []Attachment `json:"attachments"`
+	UUID          string         `json:"uuid"`
+	TestCaseID    string         `json:"testCaseId"`
+	HistoryID     string         `json:"historyId"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Status        string         `json:"status"`
+	StatusDetails *StatusDetails `json:"statusDetails,omitempty"`
+	Stage         string         `json:"stage"`
+	Steps         []Step         `json:"steps"`
+	Start         int64          `json:"start"`
+	Stop          int64          `json:"stop"`
+	FullName      string         `json:"fullName"`
+	Parameters    []Parameter    `json:"parameters"`
+	Labels        []Label        `json:"labels"`
+	Attachments   []Attachment   `json:"attachments"`
+	Links         []Link         `json:"links,omitempty"`
+
+	// File is the test file path relative to the repository root, it is not a part of the allure result.
+	File string `json:"-"`
+}
+
+type StatusDetails struct {
+	Known   bool   `json:"known"`
+	Muted   bool   `json:"muted"`
+	Flaky   bool   `json:"flaky"`
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
 }
 
 type Step struct {
-	Name        string       `json:"name"`
-	Status      string       `json:"status"`
-	Stage       string       `json:"stage"`
-	Steps       []Step       `json:"steps"`
-	Attachments []Attachment `json:"attachments"`
-	Parameters  []Parameter  `json:"parameters"`
-	Start       int64        `json:"start"`
-	Stop        int64        `json:"stop"`
+	Name          string         `json:"name"`
+	Status        string         `json:"status"`
+	StatusDetails *StatusDetails `json:"statusDetails,omitempty"`
+	Stage         string         `json:"stage"`
+	Steps         []Step         `json:"steps"`
+	Attachments   []Attachment   `json:"attachments"`
+	Parameters    []Parameter    `json:"parameters"`
+	Start         int64          `json:"start"`
+	Stop          int64          `json:"stop"`
 }
 
 type Parameter struct {
@@ -47,6 +61,12 @@ type Label struct {
 	Value string `json:"value"`
 }
 
+type Link struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Type string `json:"type,omitempty"`
+}
+
 type Attachment struct {
 	Name   string `json:"name"`
 	Source string `json:"source"`
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// version is a part of every key, it is changed with the format of the cached values.
+const version = "2"
+
+// Dir returns the default cache directory under the user cache dir.
+func Dir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("os.UserCacheDir: %w", err)
+	}
+
+	return filepath.Join(dir, "golurectl"), nil
+}
+
+// New creates a cache storing JSON encoded values in the directory.
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Cache is an on-disk key-value store. A nil Cache stores nothing.
+type Cache struct {
+	dir string
+}
+
+// Key hashes the parts into a cache key.
+func Key(parts ...string) string {
+	h := sha256.Sum256([]byte(version + "\x00" + strings.Join(parts, "\x00")))
+	return hex.EncodeToString(h[:])
+}
+
+// Get decodes the value stored under the key into v and reports whether it was found.
+func (c *Cache) Get(key string, v any) bool {
+	if c == nil {
+		return false
+	}
+
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(b, v) == nil
+}
+
+// Put stores the value under the key. The file is renamed into place to keep concurrent readers consistent.
+func (c *Cache) Put(key string, v any) error {
+	if c == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	pth := c.path(key)
+	if err = os.MkdirAll(filepath.Dir(pth), 0o755); err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(pth), "."+key+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+
+	if _, err = tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("file Write: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("file Close: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), pth); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Stamp identifies a version of a file by its modification time and size.
+type Stamp struct {
+	ModTime int64 `json:"modTime"`
+	Size    int64 `json:"size"`
+	Missing bool  `json:"missing,omitempty"`
+}
+
+// Stamps returns the stamps of the files, a missing file gets a stamp too.
+func Stamps(pths ...string) (map[string]Stamp, error) {
+	stamps := make(map[string]Stamp, len(pths))
+	for _, pth := range pths {
+		stamp, err := stat(pth)
+		if err != nil {
+			return nil, err
+		}
+
+		stamps[pth] = stamp
+	}
+
+	return stamps, nil
+}
+
+// Valid reports whether none of the files changed since the stamps were taken.
+func Valid(stamps map[string]Stamp) bool {
+	for pth, stamp := range stamps {
+		current, err := stat(pth)
+		if err != nil || current != stamp {
+			return false
+		}
+	}
+
+	return true
+}
+
+func stat(pth string) (Stamp, error) {
+	info, err := os.Stat(pth)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Stamp{Missing: true}, nil
+		}
+
+		return Stamp{}, fmt.Errorf("os.Stat: %w", err)
+	}
+
+	return Stamp{ModTime: info.ModTime().UnixNano(), Size: info.Size()}, nil
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCache_GetPut(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "a_test.go")
+	if err := os.WriteFile(pth, []byte("package a"), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	type entry struct {
+		Stamps map[string]Stamp
+		Value  []string
+	}
+
+	stamps, err := Stamps(pth, filepath.Join(dir, "missing.go"))
+	if err != nil {
+		t.Fatalf("Stamps: %v", err)
+	}
+
+	c := New(filepath.Join(dir, "cache"))
+	key := Key("test", pth)
+	expected := entry{Stamps: stamps, Value: []string{"TestA"}}
+	if err = c.Put(key, expected); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	var got entry
+	if !c.Get(key, &got) || !Valid(got.Stamps) {
+		t.Fatalf("got: no valid entry, want: cached entry")
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	if err = os.WriteFile(pth, []byte("package a\n"), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	if Valid(got.Stamps) {
+		t.Errorf("got: valid stamps, want: changed file")
+	}
+
+	if c.Get(Key("other"), &got) {
+		t.Errorf("got: entry, want: missing key")
+	}
+}
@@ -0,0 +1,164 @@
+package codeowners
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// locations are searched for the CODEOWNERS file in the order GitHub uses.
+var locations = []string{".github", "", "docs"}
+
+// Owners resolves code owners of files in a repository.
+type Owners struct {
+	root  string
+	rules []rule
+}
+
+type rule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// Load reads the CODEOWNERS file of the repository root, it returns nil if there is none.
+func Load(root string) (*Owners, error) {
+	for _, dir := range locations {
+		b, err := os.ReadFile(filepath.Join(root, dir, "CODEOWNERS"))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, fmt.Errorf("os.ReadFile: %w", err)
+		}
+
+		rules, err := parse(b)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", filepath.Join(dir, "CODEOWNERS"), err)
+		}
+
+		return &Owners{root: root, rules: rules}, nil
+	}
+
+	return nil, nil
+}
+
+// Of returns the owners of the file, the last matching rule wins.
+func (o *Owners) Of(pth string) []string {
+	if o == nil {
+		return nil
+	}
+
+	if filepath.IsAbs(pth) {
+		rel, err := filepath.Rel(o.root, pth)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return nil
+		}
+
+		pth = rel
+	}
+
+	pth = filepath.ToSlash(pth)
+	for i := len(o.rules) - 1; i >= 0; i-- {
+		if o.rules[i].pattern.MatchString(pth) {
+			return o.rules[i].owners
+		}
+	}
+
+	return nil
+}
+
+func parse(b []byte) ([]rule, error) {
+	var rules []rule
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if pos := strings.Index(text, " #"); pos >= 0 {
+			text = text[:pos]
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		pattern, err := compilePattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		// A pattern without owners removes the ownership of the matching files.
+		var owners []string
+		if len(fields) > 1 {
+			owners = fields[1:]
+		}
+
+		rules = append(rules, rule{pattern: pattern, owners: owners})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("bufio.Scanner: %w", err)
+	}
+
+	return rules, nil
+}
+
+// compilePattern translates a gitignore style pattern. Patterns with a leading or inner slash are relative to
+// the repository root, others match at any depth. A pattern matching a directory by name matches everything
+// inside it.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	// A trailing * matches the direct children only, as docs/* in GitHub CODEOWNERS.
+	switch {
+	case dirOnly:
+		expr.WriteString("/.*$")
+	case strings.HasSuffix(pattern, "*"):
+		expr.WriteString("$")
+	default:
+		expr.WriteString("(?:/.*)?$")
+	}
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("regexp.Compile: %w", err)
+	}
+
+	return re, nil
+}
@@ -0,0 +1,92 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testCodeowners = `# default owners
+*       @acme/core
+
+*.go    @acme/gophers # go files
+/internal/billing/ @acme/billing
+docs/**  @acme/docs
+docs/*   @acme/writers
+**/testdata/fixtures @acme/qa
+/internal/billing/legacy
+`
+
+func TestOwners_Of(t *testing.T) {
+	t.Parallel()
+
+	rules, err := parse([]byte(testCodeowners))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	owners := &Owners{root: "/repo", rules: rules}
+
+	testCases := []struct {
+		name     string
+		pth      string
+		expected []string
+	}{
+		{
+			name:     "test_default",
+			pth:      "README.md",
+			expected: []string{"@acme/core"},
+		},
+		{
+			name:     "test_extension_any_depth",
+			pth:      "internal/slice/slice_test.go",
+			expected: []string{"@acme/gophers"},
+		},
+		{
+			name:     "test_last_match_wins",
+			pth:      "/repo/internal/billing/invoice_test.go",
+			expected: []string{"@acme/billing"},
+		},
+		{
+			name:     "test_anchored_inner_slash",
+			pth:      "internal/docs/a.md",
+			expected: []string{"@acme/core"},
+		},
+		{
+			name:     "test_double_star_directory",
+			pth:      "pkg/api/testdata/fixtures/a.json",
+			expected: []string{"@acme/qa"},
+		},
+		{
+			name:     "test_star_direct_child",
+			pth:      "docs/index.md",
+			expected: []string{"@acme/writers"},
+		},
+		{
+			name:     "test_star_nested_file",
+			pth:      "docs/guides/install.md",
+			expected: []string{"@acme/docs"},
+		},
+		{
+			name: "test_no_owners",
+			pth:  "internal/billing/legacy/old_test.go",
+		},
+		{
+			name: "test_outside_root",
+			pth:  "/other/a.go",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				if diff := cmp.Diff(tc.expected, owners.Of(tc.pth)); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
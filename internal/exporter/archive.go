@@ -0,0 +1,180 @@
+package exporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type archive interface {
+	add(name string, body []byte) error
+	// Close finishes the archive and moves it to the final path.
+	Close() error
+	// abort removes the unfinished archive.
+	abort() error
+}
+
+// openArchive creates a tar.gz or zip archive depending on the file extension.
+// The archive is written to a temporary file renamed to the given path on Close.
+func openArchive(pth string) (archive, error) {
+	var create func(file *archiveFile) archive
+	switch name := strings.ToLower(pth); {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		create = newTarArchive
+	case strings.HasSuffix(name, ".zip"):
+		create = newZipArchive
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s", filepath.Base(pth))
+	}
+
+	dir, _ := filepath.Split(pth)
+	if dir != "" {
+		if err := mkdir(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.CreateTemp(dir, "."+filepath.Base(pth)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("os.CreateTemp: %w", err)
+	}
+
+	return create(&archiveFile{File: file, pth: pth}), nil
+}
+
+// archiveFile is the temporary file of an archive.
+type archiveFile struct {
+	*os.File
+	pth string
+}
+
+// commit syncs and closes the temporary file and renames it to the archive path.
+func (f *archiveFile) commit() error {
+	if err := f.Sync(); err != nil {
+		_ = f.abort()
+		return fmt.Errorf("file Sync: %w", err)
+	}
+
+	if err := f.File.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("file Close: %w", err)
+	}
+
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("os.Chmod: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), f.pth); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+
+	return nil
+}
+
+// abort closes and removes the temporary file.
+func (f *archiveFile) abort() error {
+	_ = f.File.Close()
+
+	if err := os.Remove(f.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("os.Remove: %w", err)
+	}
+
+	return nil
+}
+
+func newTarArchive(file *archiveFile) archive {
+	gz := gzip.NewWriter(file)
+	return &tarArchive{file: file, gz: gz, tw: tar.NewWriter(gz)}
+}
+
+type tarArchive struct {
+	file *archiveFile
+	gz   *gzip.Writer
+	tw   *tar.Writer
+}
+
+func (a *tarArchive) add(name string, body []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(body)),
+		ModTime: time.Now(),
+	}
+
+	if err := a.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("tar.Writer WriteHeader: %w", err)
+	}
+
+	if _, err := a.tw.Write(body); err != nil {
+		return fmt.Errorf("tar.Writer Write: %w", err)
+	}
+
+	return nil
+}
+
+func (a *tarArchive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		_ = a.file.abort()
+		return fmt.Errorf("tar.Writer Close: %w", err)
+	}
+
+	if err := a.gz.Close(); err != nil {
+		_ = a.file.abort()
+		return fmt.Errorf("gzip.Writer Close: %w", err)
+	}
+
+	return a.file.commit()
+}
+
+func (a *tarArchive) abort() error {
+	return a.file.abort()
+}
+
+func newZipArchive(file *archiveFile) archive {
+	return &zipArchive{file: file, zw: zip.NewWriter(file)}
+}
+
+type zipArchive struct {
+	file *archiveFile
+	zw   *zip.Writer
+}
+
+func (a *zipArchive) add(name string, body []byte) error {
+	w, err := a.zw.CreateHeader(
+		&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("zip.Writer CreateHeader: %w", err)
+	}
+
+	if _, err = w.Write(body); err != nil {
+		return fmt.Errorf("zip.Writer Write: %w", err)
+	}
+
+	return nil
+}
+
+func (a *zipArchive) Close() error {
+	if err := a.zw.Close(); err != nil {
+		_ = a.file.abort()
+		return fmt.Errorf("zip.Writer Close: %w", err)
+	}
+
+	return a.file.commit()
+}
+
+func (a *zipArchive) abort() error {
+	return a.file.abort()
+}
@@ -0,0 +1,267 @@
+package exporter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+func TestWriter_Archive(t *testing.T) {
+	t.Parallel()
+
+	tests := []allure.Test{{UUID: "1", Name: "TestA"}, {UUID: "2", Name: "TestB"}}
+	attachments := []Attachment{{Name: "TestB", Source: "b-attachment.txt", Body: []byte("log")}}
+
+	testCases := []struct {
+		name string
+		file string
+		read func(t *testing.T, pth string) map[string][]byte
+	}{
+		{
+			name: "test_tar_gz",
+			file: "results.tar.gz",
+			read: readTarArchive,
+		},
+		{
+			name: "test_tgz",
+			file: "results.tgz",
+			read: readTarArchive,
+		},
+		{
+			name: "test_zip",
+			file: "results.zip",
+			read: readZipArchive,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				dir := t.TempDir()
+				pth := filepath.Join(dir, tc.file)
+				w := NewWriter(WriteArchiveTo(pth))
+
+				ctx := context.Background()
+				if err := w.WriteReport(ctx, tests); err != nil {
+					t.Fatalf("WriteReport: %v", err)
+				}
+
+				if err := w.WriteAttachments(ctx, attachments); err != nil {
+					t.Fatalf("WriteAttachments: %v", err)
+				}
+
+				if err := w.WriteEnvironment(ctx, map[string]string{"go.version": "go1.20"}); err != nil {
+					t.Fatalf("WriteEnvironment: %v", err)
+				}
+
+				// The archive is moved to the final path only on Flush.
+				if _, err := os.Stat(pth); !errors.Is(err, os.ErrNotExist) {
+					t.Fatalf("got: %v, want: the archive is not written before Flush", err)
+				}
+
+				if err := w.Flush(ctx); err != nil {
+					t.Fatalf("Flush: %v", err)
+				}
+
+				files := tc.read(t, pth)
+
+				names := make([]string, 0, len(files))
+				for name := range files {
+					names = append(names, name)
+				}
+
+				sort.Strings(names)
+
+				expected := []string{"1-result.json", "2-result.json", "b-attachment.txt", environmentFile}
+				if diff := cmp.Diff(expected, names); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				var result allure.Test
+				if err := json.Unmarshal(files["2-result.json"], &result); err != nil {
+					t.Fatalf("json.Unmarshal: %v", err)
+				}
+
+				if diff := cmp.Diff(tests[1], result); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				if diff := cmp.Diff("log", string(files["b-attachment.txt"])); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				if diff := cmp.Diff([]string{tc.file}, dirEntries(t, dir)); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+func TestWriter_ArchiveAbort(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		write func(ctx context.Context, w Writer) error
+	}{
+		{
+			name: "test_write_attachments",
+			write: func(ctx context.Context, w Writer) error {
+				return w.WriteAttachments(ctx, []Attachment{{Source: "a-attachment.txt", Body: []byte("log")}})
+			},
+		},
+		{
+			name: "test_write_environment",
+			write: func(ctx context.Context, w Writer) error {
+				return w.WriteEnvironment(ctx, map[string]string{"go.version": "go1.20"})
+			},
+		},
+		{
+			name: "test_flush",
+			write: func(ctx context.Context, w Writer) error {
+				return w.Flush(ctx)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				dir := t.TempDir()
+				pth := filepath.Join(dir, "results.zip")
+				w := NewWriter(WriteArchiveTo(pth))
+
+				if err := w.WriteReport(context.Background(), []allure.Test{{UUID: "1"}}); err != nil {
+					t.Fatalf("WriteReport: %v", err)
+				}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				if err := tc.write(ctx, w); !errors.Is(err, context.Canceled) {
+					t.Fatalf("got: %v, want: %v", err, context.Canceled)
+				}
+
+				if entries := dirEntries(t, dir); len(entries) > 0 {
+					t.Errorf("got: %v, want: the unfinished archive is removed", entries)
+				}
+			},
+		)
+	}
+}
+
+func TestOpenArchive_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if _, err := openArchive(filepath.Join(dir, "results.rar")); err == nil {
+		t.Fatalf("got: nil, want: unsupported archive format error")
+	}
+
+	if entries := dirEntries(t, dir); len(entries) > 0 {
+		t.Errorf("got: %v, want: no files", entries)
+	}
+}
+
+func readTarArchive(t *testing.T, pth string) map[string][]byte {
+	t.Helper()
+
+	file, err := os.Open(pth)
+	if err != nil {
+		t.Fatalf("os.Open: %v", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("tar.Reader Next: %v", err)
+		}
+
+		body, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("io.ReadAll: %v", err)
+		}
+
+		files[hdr.Name] = body
+	}
+
+	return files
+}
+
+func readZipArchive(t *testing.T, pth string) map[string][]byte {
+	t.Helper()
+
+	zr, err := zip.OpenReader(pth)
+	if err != nil {
+		t.Fatalf("zip.OpenReader: %v", err)
+	}
+	defer zr.Close()
+
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("zip.File Open: %v", err)
+		}
+
+		body, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("io.ReadAll: %v", err)
+		}
+
+		files[f.Name] = body
+	}
+
+	return files
+}
+
+// dirEntries returns the sorted names of the files in the directory.
+func dirEntries(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("os.ReadDir: %v", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,132 @@
+package exporter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+const truncationMarker = "\n... [truncated %d bytes] ...\n"
+
+// Truncation describes an attachment body cut down by the size limits,
+// an attachment dropped because the total size is used up has no source.
+type Truncation struct {
+	Name    string
+	Source  string
+	Size    int
+	Written int
+}
+
+func newAttachmentStore(opts Options, ch chan<- Attachment) *attachmentStore {
+	return &attachmentStore{
+		maxSize:   opts.attachmentMaxSize,
+		totalSize: opts.attachmentTotalSize,
+		hashes:    make(map[[sha256.Size]byte]Attachment),
+		ch:        ch,
+	}
+}
+
+// attachmentStore applies size limits to attachments and deduplicates identical bodies.
+type attachmentStore struct {
+	maxSize   int
+	totalSize int
+	written   int
+
+	hashes       map[[sha256.Size]byte]Attachment
+	truncated    []Truncation
+	deduplicated int
+
+	ch chan<- Attachment
+}
+
+// add sends a new attachment to the channel unless an identical body was already attached
+// and returns the reference for the allure result. The MIME type is detected if it is empty.
+// It returns false if the attachment is dropped because the total size is used up.
+func (s *attachmentStore) add(name, mimeType string, body []byte) (allure.Attachment, bool) {
+	size := len(body)
+
+	var truncated bool
+	if s.maxSize > 0 && len(body) > s.maxSize {
+		body, truncated = truncate(body, s.maxSize), true
+	}
+
+	if mimeType == "" {
+		mimeType = DetectMime(body)
+	}
+
+	// Identical bodies share a single attachment file.
+	hash := sha256.Sum256(append([]byte(mimeType+"\x00"), body...))
+	if attachment, ok := s.hashes[hash]; ok {
+		s.deduplicated++
+		attachment.Name = name
+		return attachment.allureAttachment(), true
+	}
+
+	// Cut the body to the rest of the total budget or drop it if the rest cannot even hold the marker.
+	if s.totalSize > 0 && s.written+len(body) > s.totalSize {
+		if s.totalSize-s.written < len(fmt.Sprintf(truncationMarker, len(body))) {
+			s.truncated = append(s.truncated, Truncation{Name: name, Size: size})
+			return allure.Attachment{}, false
+		}
+
+		body, truncated = truncate(body, s.totalSize-s.written), true
+	}
+
+	attachment := NewAttachment(name, mimeType, body)
+	if truncated {
+		s.truncated = append(
+			s.truncated, Truncation{
+				Name:    name,
+				Source:  attachment.Source,
+				Size:    size,
+				Written: len(body),
+			},
+		)
+	}
+
+	s.written += len(body)
+	s.hashes[hash] = attachment
+	s.ch <- attachment
+
+	return attachment.allureAttachment(), true
+}
+
+// truncate keeps the head and the tail of the body within the limit, cut at line boundaries when possible,
+// and puts a marker with the number of dropped bytes in between. The result including the marker
+// never exceeds the limit, a limit too small for the marker just cuts the head.
+func truncate(body []byte, limit int) []byte {
+	if limit < 0 {
+		limit = 0
+	}
+
+	if len(body) <= limit {
+		return body
+	}
+
+	// The marker never grows beyond the one for the whole body.
+	budget := limit - len(fmt.Sprintf(truncationMarker, len(body)))
+	if budget <= 0 {
+		return body[:limit]
+	}
+
+	headSize, tailSize := budget/2, budget-budget/2
+
+	head := body[:headSize]
+	if pos := bytes.LastIndexByte(head, '\n'); pos > 0 {
+		head = head[:pos+1]
+	}
+
+	tail := body[len(body)-tailSize:]
+	if pos := bytes.IndexByte(tail, '\n'); pos >= 0 && pos < len(tail)-1 {
+		tail = tail[pos+1:]
+	}
+
+	result := make([]byte, 0, limit)
+	result = append(result, head...)
+	result = append(result, fmt.Sprintf(truncationMarker, len(body)-len(head)-len(tail))...)
+	result = append(result, tail...)
+
+	return result
+}
@@ -0,0 +1,234 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	lines := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		lines = append(lines, fmt.Sprintf("line %02d", i))
+	}
+
+	log := []byte(strings.Join(lines, "\n") + "\n")
+
+	testCases := []struct {
+		name     string
+		body     []byte
+		limit    int
+		expected string
+	}{
+		{
+			name:     "test_within_limit",
+			body:     []byte("short log\n"),
+			limit:    100,
+			expected: "short log\n",
+		},
+		{
+			name:     "test_exact_limit",
+			body:     []byte("short log\n"),
+			limit:    10,
+			expected: "short log\n",
+		},
+		{
+			name:  "test_head_and_tail",
+			body:  log,
+			limit: 100,
+			expected: "line 00\nline 01\nline 02\nline 03\n" +
+				fmt.Sprintf(truncationMarker, 736) +
+				"line 96\nline 97\nline 98\nline 99\n",
+		},
+		{
+			name:     "test_limit_below_marker",
+			body:     log,
+			limit:    10,
+			expected: "line 00\nli",
+		},
+		{
+			name:     "test_zero_limit",
+			body:     log,
+			limit:    0,
+			expected: "",
+		},
+		{
+			name:     "test_negative_limit",
+			body:     log,
+			limit:    -1,
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				got := truncate(tc.body, tc.limit)
+				if diff := cmp.Diff(tc.expected, string(got)); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				if tc.limit >= 0 && len(got) > tc.limit {
+					t.Errorf("got: %d bytes, want: at most %d", len(got), tc.limit)
+				}
+			},
+		)
+	}
+}
+
+func TestAttachmentStore(t *testing.T) {
+	t.Parallel()
+
+	log := bytes.Repeat([]byte("0123456789abcdef\n"), 20)
+
+	type add struct {
+		name string
+		body []byte
+	}
+
+	testCases := []struct {
+		name                 string
+		opts                 Options
+		adds                 []add
+		expectedAdded        []bool
+		expectedSizes        []int
+		expectedTruncated    []Truncation
+		expectedDeduplicated int
+	}{
+		{
+			name:          "test_no_limits",
+			adds:          []add{{name: "TestA", body: log}},
+			expectedAdded: []bool{true},
+			expectedSizes: []int{len(log)},
+		},
+		{
+			name:              "test_max_size",
+			opts:              Options{attachmentMaxSize: 100},
+			adds:              []add{{name: "TestA", body: log}, {name: "TestB", body: []byte("short")}},
+			expectedAdded:     []bool{true, true},
+			expectedSizes:     []int{99, 5},
+			expectedTruncated: []Truncation{{Name: "TestA", Size: len(log), Written: 99}},
+		},
+		{
+			name: "test_total_size",
+			opts: Options{attachmentTotalSize: 300},
+			adds: []add{
+				{name: "TestA", body: log[:200]},
+				{name: "TestB", body: log},
+				{name: "TestC", body: []byte("short")},
+			},
+			expectedAdded: []bool{true, true, false},
+			expectedSizes: []int{200, 99},
+			expectedTruncated: []Truncation{
+				{Name: "TestB", Size: len(log), Written: 99},
+				{Name: "TestC", Size: 5},
+			},
+		},
+		{
+			name: "test_total_size_used_up",
+			opts: Options{attachmentTotalSize: 200},
+			adds: []add{
+				{name: "TestA", body: log[:200]},
+				{name: "TestB", body: log},
+			},
+			expectedAdded:     []bool{true, false},
+			expectedSizes:     []int{200},
+			expectedTruncated: []Truncation{{Name: "TestB", Size: len(log)}},
+		},
+		{
+			name: "test_deduplicated",
+			opts: Options{attachmentTotalSize: 300},
+			adds: []add{
+				{name: "TestA", body: log[:200]},
+				{name: "TestB", body: log[:200]},
+				{name: "TestC", body: []byte("short")},
+			},
+			expectedAdded:        []bool{true, true, true},
+			expectedSizes:        []int{200, 5},
+			expectedDeduplicated: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				ch := make(chan Attachment, len(tc.adds))
+				store := newAttachmentStore(tc.opts, ch)
+
+				added := make([]bool, 0, len(tc.adds))
+				sources := make(map[string]string)
+				for _, a := range tc.adds {
+					attachment, ok := store.add(a.name, "", a.body)
+					added = append(added, ok)
+
+					if ok {
+						if attachment.Name != a.name {
+							t.Errorf("got: %s, want: %s", attachment.Name, a.name)
+						}
+
+						sources[a.name] = attachment.Source
+					}
+				}
+
+				close(ch)
+
+				sizes := make([]int, 0)
+				var total int
+				for attachment := range ch {
+					sizes = append(sizes, len(attachment.Body))
+					total += len(attachment.Body)
+
+					if tc.opts.attachmentMaxSize > 0 && len(attachment.Body) > tc.opts.attachmentMaxSize {
+						t.Errorf("got: %d bytes, want: at most %d", len(attachment.Body), tc.opts.attachmentMaxSize)
+					}
+				}
+
+				if tc.opts.attachmentTotalSize > 0 && total > tc.opts.attachmentTotalSize {
+					t.Errorf("got: %d bytes in total, want: at most %d", total, tc.opts.attachmentTotalSize)
+				}
+
+				if diff := cmp.Diff(tc.expectedAdded, added); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				if diff := cmp.Diff(tc.expectedSizes, sizes); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				truncated := make([]Truncation, 0, len(store.truncated))
+				for _, tr := range store.truncated {
+					if tr.Source != sources[tr.Name] {
+						t.Errorf("got: %s, want: %s", tr.Source, sources[tr.Name])
+					}
+
+					tr.Source = ""
+					truncated = append(truncated, tr)
+				}
+
+				if diff := cmp.Diff(tc.expectedTruncated, truncated, cmpopts.EquateEmpty()); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				if store.deduplicated != tc.expectedDeduplicated {
+					t.Errorf("got: %d, want: %d", store.deduplicated, tc.expectedDeduplicated)
+				}
+
+				if tc.expectedDeduplicated > 0 && sources["TestA"] != sources["TestB"] {
+					t.Errorf("got: %s and %s, want: a shared source", sources["TestA"], sources["TestB"])
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,171 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+const ctrfToolName = "golurectl"
+
+const (
+	ctrfStatusPassed  = "passed"
+	ctrfStatusFailed  = "failed"
+	ctrfStatusSkipped = "skipped"
+	ctrfStatusOther   = "other"
+)
+
+// ctrfReport is the Common Test Report Format document, see https://ctrf.io.
+type ctrfReport struct {
+	Results ctrfResults `json:"results"`
+}
+
+type ctrfResults struct {
+	Tool        ctrfTool          `json:"tool"`
+	Summary     ctrfSummary       `json:"summary"`
+	Tests       []ctrfTest        `json:"tests"`
+	Environment map[string]string `json:"environment,omitempty"`
+}
+
+type ctrfTool struct {
+	Name string `json:"name"`
+}
+
+type ctrfSummary struct {
+	Tests   int   `json:"tests"`
+	Passed  int   `json:"passed"`
+	Failed  int   `json:"failed"`
+	Pending int   `json:"pending"`
+	Skipped int   `json:"skipped"`
+	Other   int   `json:"other"`
+	Start   int64 `json:"start"`
+	Stop    int64 `json:"stop"`
+}
+
+type ctrfTest struct {
+	Name      string   `json:"name"`
+	Status    string   `json:"status"`
+	Duration  int64    `json:"duration"`
+	Start     int64    `json:"start,omitempty"`
+	Stop      int64    `json:"stop,omitempty"`
+	Suite     string   `json:"suite,omitempty"`
+	Message   string   `json:"message,omitempty"`
+	Trace     string   `json:"trace,omitempty"`
+	RawStatus string   `json:"rawStatus,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Type      string   `json:"type,omitempty"`
+	FilePath  string   `json:"filePath,omitempty"`
+	Flaky     bool     `json:"flaky,omitempty"`
+	Stdout    []string `json:"stdout,omitempty"`
+}
+
+// writeCTRF writes the collected tests as a CTRF JSON report.
+func (o *writer) writeCTRF() error {
+	dir, _ := filepath.Split(o.ctrfPth)
+	if dir != "" {
+		if err := mkdir(dir); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.MarshalIndent(o.ctrfReport(), "", "    ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+
+	if err = os.WriteFile(o.ctrfPth, b, 0o644); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+
+	return nil
+}
+
+func (o *writer) ctrfReport() ctrfReport {
+	results := ctrfResults{
+		Tool:  ctrfTool{Name: ctrfToolName},
+		Tests: make([]ctrfTest, 0, len(o.tests)),
+	}
+
+	summary := &results.Summary
+	for _, tc := range o.tests {
+		test := ctrfTest{
+			Name:      tc.Name,
+			Status:    ctrfStatus(tc.Status),
+			Duration:  tc.Stop - tc.Start,
+			Start:     tc.Start,
+			Stop:      tc.Stop,
+			Suite:     testSuite(tc),
+			RawStatus: tc.Status,
+			Type:      "unit",
+			FilePath:  tc.File,
+		}
+
+		if details := tc.StatusDetails; details != nil {
+			test.Message = details.Message
+			test.Trace = details.Trace
+			test.Flaky = details.Flaky
+		}
+
+		for _, label := range tc.Labels {
+			if label.Name == "tag" {
+				test.Tags = append(test.Tags, label.Value)
+			}
+		}
+
+		if log := attachmentsLog(tc.Name, tc.Attachments, o.logs); log != "" {
+			test.Stdout = strings.Split(strings.TrimRight(log, "\n"), "\n")
+		}
+
+		switch test.Status {
+		case ctrfStatusPassed:
+			summary.Passed++
+		case ctrfStatusFailed:
+			summary.Failed++
+		case ctrfStatusSkipped:
+			summary.Skipped++
+		default:
+			summary.Other++
+		}
+
+		if summary.Start == 0 || tc.Start < summary.Start {
+			summary.Start = tc.Start
+		}
+
+		if tc.Stop > summary.Stop {
+			summary.Stop = tc.Stop
+		}
+
+		results.Tests = append(results.Tests, test)
+	}
+
+	summary.Tests = len(results.Tests)
+
+	return ctrfReport{Results: results}
+}
+
+// ctrfStatus maps allure statuses to CTRF ones, broken tests are reported as failed.
+func ctrfStatus(status string) string {
+	switch status {
+	case allure.StatusPass:
+		return ctrfStatusPassed
+	case allure.StatusFail, allure.StatusBroken:
+		return ctrfStatusFailed
+	case allure.StatusSkip:
+		return ctrfStatusSkipped
+	default:
+		return ctrfStatusOther
+	}
+}
+
+// testSuite returns the allure suite label of the test or its package.
+func testSuite(tc allure.Test) string {
+	if suite, ok := labelValue(tc.Labels, "suite"); ok {
+		return suite
+	}
+
+	return testPackage(tc)
+}
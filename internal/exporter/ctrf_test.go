@@ -0,0 +1,208 @@
+package exporter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/allure"
+	"github.com/robotomize/go-allure/internal/git"
+	"github.com/robotomize/go-allure/internal/parser"
+)
+
+func TestWriter_WriteCTRF(t *testing.T) {
+	t.Parallel()
+
+	pth := filepath.Join(t.TempDir(), "reports", "ctrf.json")
+	o := &writer{
+		ctrfPth: pth,
+		tests: []allure.Test{
+			{
+				Name:   "TestFilter",
+				Status: allure.StatusFail,
+				Start:  1000,
+				Stop:   1500,
+				File:   "internal/slice/slice_test.go",
+				Labels: []allure.Label{
+					{Name: "package", Value: "slice"},
+					{Name: "tag", Value: "unit"},
+					{Name: "tag", Value: "slow"},
+				},
+				StatusDetails: &allure.StatusDetails{Message: "got: [3 4], want: [3]", Trace: "slice_test.go:96", Flaky: true},
+				Attachments:   []allure.Attachment{{Name: "TestFilter", Source: "filter-attachment.txt"}},
+			},
+			{
+				Name:   "TestMap",
+				Status: allure.StatusPass,
+				Start:  900,
+				Stop:   1000,
+				Labels: []allure.Label{{Name: "package", Value: "slice"}, {Name: "suite", Value: "mapping"}},
+			},
+			{Name: "TestPanic", Status: allure.StatusBroken, Start: 2000, Stop: 2250},
+			{Name: "TestSkip", Status: allure.StatusSkip, Start: 2250, Stop: 2250},
+			{Name: "TestUnknown", Status: "unknown", Start: 2250, Stop: 2300},
+		},
+		logs: map[string][]byte{"filter-attachment.txt": []byte("=== RUN   TestFilter\n--- FAIL: TestFilter\n")},
+	}
+
+	expected := ctrfReport{
+		Results: ctrfResults{
+			Tool: ctrfTool{Name: ctrfToolName},
+			Summary: ctrfSummary{
+				Tests:   5,
+				Passed:  1,
+				Failed:  2,
+				Skipped: 1,
+				Other:   1,
+				Start:   900,
+				Stop:    2300,
+			},
+			Tests: []ctrfTest{
+				{
+					Name:      "TestFilter",
+					Status:    ctrfStatusFailed,
+					Duration:  500,
+					Start:     1000,
+					Stop:      1500,
+					Suite:     "slice",
+					Message:   "got: [3 4], want: [3]",
+					Trace:     "slice_test.go:96",
+					RawStatus: allure.StatusFail,
+					Tags:      []string{"unit", "slow"},
+					Type:      "unit",
+					FilePath:  "internal/slice/slice_test.go",
+					Flaky:     true,
+					Stdout:    []string{"=== RUN   TestFilter", "--- FAIL: TestFilter"},
+				},
+				{
+					Name:      "TestMap",
+					Status:    ctrfStatusPassed,
+					Duration:  100,
+					Start:     900,
+					Stop:      1000,
+					Suite:     "mapping",
+					RawStatus: allure.StatusPass,
+					Type:      "unit",
+				},
+				{
+					Name:      "TestPanic",
+					Status:    ctrfStatusFailed,
+					Duration:  250,
+					Start:     2000,
+					Stop:      2250,
+					Suite:     defaultPackageName,
+					RawStatus: allure.StatusBroken,
+					Type:      "unit",
+				},
+				{
+					Name:      "TestSkip",
+					Status:    ctrfStatusSkipped,
+					Start:     2250,
+					Stop:      2250,
+					Suite:     defaultPackageName,
+					RawStatus: allure.StatusSkip,
+					Type:      "unit",
+				},
+				{
+					Name:      "TestUnknown",
+					Status:    ctrfStatusOther,
+					Duration:  50,
+					Start:     2250,
+					Stop:      2300,
+					Suite:     defaultPackageName,
+					RawStatus: "unknown",
+					Type:      "unit",
+				},
+			},
+		},
+	}
+
+	if err := o.writeCTRF(); err != nil {
+		t.Fatalf("writeCTRF: %v", err)
+	}
+
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		t.Fatalf("os.ReadFile: %v", err)
+	}
+
+	var got ctrfReport
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestExporter_TestFilePath(t *testing.T) {
+	t.Parallel()
+
+	file := parser.GoTestMethod{
+		FileName:  "slice_test.go",
+		Dir:       "/repo/tools/internal/slice",
+		ModuleDir: "/repo/tools",
+	}
+
+	testCases := []struct {
+		name     string
+		exporter *exporter
+		file     parser.GoTestMethod
+		expected string
+	}{
+		{
+			name:     "test_module_root",
+			exporter: &exporter{},
+			file:     file,
+			expected: "internal/slice/slice_test.go",
+		},
+		{
+			name: "test_repository_root",
+			exporter: &exporter{
+				git: map[string]gitMetadata{"sliceTestFilter": {repo: &git.Repository{Root: "/repo"}}},
+			},
+			file:     file,
+			expected: "tools/internal/slice/slice_test.go",
+		},
+		{
+			name: "test_source_links_root",
+			exporter: &exporter{
+				opts: Options{sourceLinks: &SourceLinks{Root: "/repo"}},
+			},
+			file:     file,
+			expected: "tools/internal/slice/slice_test.go",
+		},
+		{
+			name:     "test_unknown_module",
+			exporter: &exporter{},
+			file:     parser.GoTestMethod{FileName: "slice_test.go", Dir: "/repo/internal/slice"},
+			expected: "slice_test.go",
+		},
+		{
+			name: "test_outside_root",
+			exporter: &exporter{
+				opts: Options{sourceLinks: &SourceLinks{Root: "/other"}},
+			},
+			file:     file,
+			expected: "slice_test.go",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				got := tc.exporter.testFilePath("sliceTestFilter", tc.file)
+				if diff := cmp.Diff(tc.expected, got); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
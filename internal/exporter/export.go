@@ -5,21 +5,31 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/robotomize/go-allure/internal/allure"
+	"github.com/robotomize/go-allure/internal/codeowners"
+	"github.com/robotomize/go-allure/internal/golist"
 	"github.com/robotomize/go-allure/internal/gotest"
 	"github.com/robotomize/go-allure/internal/parser"
+	"github.com/robotomize/go-allure/internal/redact"
+	"github.com/robotomize/go-allure/internal/rules"
 )
 
 var hostname string
 
+var failureLineRegexp = regexp.MustCompile(`^\S+\.go:\d+: `)
+
 func init() {
 	hostname, _ = os.Hostname()
 }
@@ -36,13 +46,32 @@ type Report struct {
 	OutputLog   io.Reader
 	Attachments []Attachment
 	Tests       []allure.Test
+
+	// MetadataErr is set if go list is unavailable, the tests are exported without metadata then.
+	MetadataErr error
+
+	// Environment holds the entries of environment.properties.
+	Environment map[string]string
+
+	// Truncated lists attachments cut by the size limits, Deduplicated counts attachments sharing a body.
+	Truncated    []Truncation
+	Deduplicated int
 }
 
 type Option func(options *Options)
 
 type Options struct {
-	forceAttachment bool
-	allureLabels    []allure.Label
+	forceAttachment     bool
+	allureLabels        []allure.Label
+	attachmentMaxSize   int
+	attachmentTotalSize int
+	redactor            *redact.Redactor
+	labelRules          rules.Rules
+	codeOwners          *codeowners.Owners
+	gitMetadata         bool
+	sourceLinks         *SourceLinks
+	sourceAttachment    bool
+	failureSources      bool
 }
 
 func WithForceAttachment() Option {
@@ -57,12 +86,69 @@ func WithAllureLabels(labels ...allure.Label) Option {
 	}
 }
 
+// WithAttachmentLimits caps the size of a single attachment and of all attachments in bytes, zero means unlimited.
+func WithAttachmentLimits(maxSize, totalSize int) Option {
+	return func(options *Options) {
+		options.attachmentMaxSize = maxSize
+		options.attachmentTotalSize = totalSize
+	}
+}
+
+// WithRedactor masks secrets in attachments, status details and the forwarded go test log.
+func WithRedactor(redactor *redact.Redactor) Option {
+	return func(options *Options) {
+		options.redactor = redactor
+	}
+}
+
+// WithLabelRules adds labels of the rules matching the package, the test file or the test name.
+func WithLabelRules(labelRules rules.Rules) Option {
+	return func(options *Options) {
+		options.labelRules = labelRules
+	}
+}
+
+// WithCodeOwners adds owner labels of the test files from the CODEOWNERS file.
+func WithCodeOwners(owners *codeowners.Owners) Option {
+	return func(options *Options) {
+		options.codeOwners = owners
+	}
+}
+
+// WithGitMetadata adds the commit, branch, repository URL and test author labels and environment entries.
+func WithGitMetadata() Option {
+	return func(options *Options) {
+		options.gitMetadata = true
+	}
+}
+
+// WithSourceLinks adds a link to the test function in the VCS web UI.
+func WithSourceLinks(links SourceLinks) Option {
+	return func(options *Options) {
+		options.sourceLinks = &links
+	}
+}
+
+// WithSourceAttachment attaches the source code of the test function.
+func WithSourceAttachment() Option {
+	return func(options *Options) {
+		options.sourceAttachment = true
+	}
+}
+
+// WithFailureSources attaches source excerpts of the file:line locations in failure logs.
+func WithFailureSources() Option {
+	return func(options *Options) {
+		options.failureSources = true
+	}
+}
+
 type Reader interface {
 	ReadAll(ctx context.Context) (gotest.Set, error)
 }
 
 type FileParser interface {
-	ParseFiles(ctx context.Context) ([]parser.GoTestMethod, error)
+	ParseFiles(ctx context.Context, importPaths ...string) ([]parser.GoTestMethod, error)
 }
 
 type AllureExporter interface {
@@ -75,6 +161,8 @@ func New(fileParser FileParser, reader Reader, opts ...Option) AllureExporter {
 		stdinReader: reader,
 		fileParser:  fileParser,
 		files:       make(map[string]parser.GoTestMethod),
+		git:         make(map[string]gitMetadata),
+		sources:     make(map[string][]string),
 	}
 
 	for _, o := range opts {
@@ -92,21 +180,14 @@ type exporter struct {
 	stdinReader Reader
 	tests       []gotest.NestedTest
 	files       map[string]parser.GoTestMethod
+	git         map[string]gitMetadata
+	sources     map[string][]string
+	metadataErr error
 }
 
-// Read reads the test files using the file parser, saves them in a map and reads the test output from stdi.
+// Read reads the test output from stdin, then parses the test files of the tested packages
+// using the file parser and saves them in a map.
 func (e *exporter) Read(ctx context.Context) error {
-	// Parse the files using the file parser and save them in a map.
-	files, err := e.fileParser.ParseFiles(ctx)
-	if err != nil {
-		return fmt.Errorf("go parser ParseFiles: %w", err)
-	}
-
-	for _, file := range files {
-		key := file.PackageName + file.TestName
-		e.files[key] = file
-	}
-
 	// Read the test output from stdin using the stdin reader and save the results in the exporter.
 	set, err := e.stdinReader.ReadAll(ctx)
 	if err != nil {
@@ -116,6 +197,48 @@ func (e *exporter) Read(ctx context.Context) error {
 	e.tests = make([]gotest.NestedTest, len(set.Tests))
 	copy(e.tests, set.Tests)
 
+	// Collect the tested packages to parse only their files.
+	packages := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, tc := range e.tests {
+		if _, ok := seen[tc.Value.Package]; ok || tc.Value.Package == "" {
+			continue
+		}
+
+		seen[tc.Value.Package] = struct{}{}
+		packages = append(packages, tc.Value.Package)
+	}
+
+	if len(packages) > 0 {
+		// Parse the files using the file parser and save them in a map.
+		// Without the sources or the Go toolchain the tests are exported without metadata,
+		// other errors, e.g. of a metadata file, fail the export.
+		files, err := e.fileParser.ParseFiles(ctx, packages...)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+
+			if !errors.Is(err, golist.ErrUnavailable) {
+				return fmt.Errorf("go parser ParseFiles: %w", err)
+			}
+
+			e.metadataErr = fmt.Errorf("go parser ParseFiles: %w", err)
+		}
+
+		for _, file := range files {
+			key := file.PackageName + file.TestName
+			e.files[key] = file
+		}
+	}
+
+	// Read the git metadata of the test files.
+	if e.opts.gitMetadata {
+		if err = e.readGit(ctx); err != nil {
+			return fmt.Errorf("read git: %w", err)
+		}
+	}
+
 	e.readErr = set.Err
 	e.originLog = set.OriginLog
 
@@ -125,11 +248,24 @@ func (e *exporter) Read(ctx context.Context) error {
 // Export converts Go test results to Allure test report format.
 func (e *exporter) Export() (Report, error) {
 	result := Report{
-		Err:       e.readErr,
-		OutputLog: e.originLog,
+		Err:         e.readErr,
+		MetadataErr: e.metadataErr,
+		OutputLog:   e.originLog,
+		Environment: e.gitEnvironment(),
+	}
+
+	// Mask secrets in the forwarded go test log.
+	if e.opts.redactor != nil && e.originLog != nil {
+		originLog, err := io.ReadAll(e.originLog)
+		if err != nil {
+			return Report{}, fmt.Errorf("io.ReadAll: %w", err)
+		}
+
+		result.OutputLog = bytes.NewReader(e.opts.redactor.Redact(originLog))
 	}
 
 	attachmentCh := make(chan Attachment)
+	attachments := newAttachmentStore(e.opts, attachmentCh)
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
 
@@ -152,23 +288,25 @@ func (e *exporter) Export() (Report, error) {
 	// Iterate through each Go test case and create an Allure test case with associated metadata and attachments.
 	for _, testCase := range e.tests {
 		goTest := testCase.Value
+		log := e.opts.redactor.Redact(testCase.Log)
 
 		// Generate a unique ID for the Allure test case and determine its status based on the Go test status.
 		id := uuid.New().String()
-		status := e.convertStatus(goTest, testCase.Log)
+		status := e.convertStatus(goTest, log)
 		if len(status) == 0 {
 			continue
 		}
 
 		allureTestCase := allure.Test{
-			UUID:        id,
-			Name:        goTest.Name,
-			Status:      status,
-			Stage:       allure.StageFinished,
-			Steps:       make([]allure.Step, 0),
-			Labels:      make([]allure.Label, 0),
-			Parameters:  make([]allure.Parameter, 0),
-			Attachments: make([]allure.Attachment, 0),
+			UUID:          id,
+			Name:          goTest.Name,
+			Status:        status,
+			StatusDetails: e.statusDetails(status, log),
+			Stage:         allure.StageFinished,
+			Steps:         make([]allure.Step, 0),
+			Labels:        make([]allure.Label, 0),
+			Parameters:    make([]allure.Parameter, 0),
+			Attachments:   make([]allure.Attachment, 0),
 		}
 
 		// Add default labels to the Allure test case
@@ -178,6 +316,23 @@ func (e *exporter) Export() (Report, error) {
 		if ok {
 			allureTestCase.Description = goTestFile.TestComment
 			allureTestCase.FullName = fmt.Sprintf("%s/%s:%s", goTestFile.PackageName, goTestFile.FileName, goTest.Name)
+			allureTestCase.File = e.testFilePath(goTest.Package+goTest.Name, goTestFile)
+
+			if e.opts.sourceLinks != nil {
+				if link, ok := e.opts.sourceLinks.link(goTestFile); ok {
+					allureTestCase.Links = append(allureTestCase.Links, link)
+				}
+			}
+
+			if e.opts.sourceAttachment && goTestFile.Source != "" {
+				if source, ok := attachments.add(goTest.Name+".go", MimeGo, []byte(goTestFile.Source)); ok {
+					allureTestCase.Attachments = append(allureTestCase.Attachments, source)
+				}
+			}
+
+			if e.opts.failureSources && (status == allure.StatusFail || status == allure.StatusBroken) {
+				e.failureSources(&allureTestCase, log, goTestFile, attachments)
+			}
 		}
 
 		// Calculate test case ID as test case full name
@@ -194,39 +349,31 @@ func (e *exporter) Export() (Report, error) {
 		// Also, add a corresponding attachment to the Allure test case to enable viewing of the test case log in the report.
 		hasAttachment := e.opts.forceAttachment || goTest.Status == gotest.ActionPanic || goTest.Status == gotest.ActionFail
 		if hasAttachment {
-			source := fmt.Sprintf("%s-attachment.txt", uuid.New().String())
-			mime := "application/json"
-			attachmentCh <- Attachment{
-				Name:   goTest.Name,
-				Mime:   mime,
-				Source: source,
-				Body:   testCase.Log,
+			if attachment, ok := attachments.add(goTest.Name, "", log); ok {
+				allureTestCase.Attachments = append(allureTestCase.Attachments, attachment)
 			}
-			allureTestCase.Attachments = append(
-				allureTestCase.Attachments, allure.Attachment{
-					Name:   goTest.Name,
-					Source: source,
-					Type:   "application/json",
-				},
-			)
 		}
 
 		// Add test steps to the Allure test case and add it to the Report.
-		e.addStep(&allureTestCase, testCase, attachmentCh)
+		e.addStep(&allureTestCase, testCase, attachments)
 		result.Tests = append(result.Tests, allureTestCase)
 	}
 
 	close(attachmentCh)
 	wg.Wait()
 
+	result.Truncated = attachments.truncated
+	result.Deduplicated = attachments.deduplicated
+
 	return result, nil
 }
 
 // addStep appends Allure test steps to a given Allure object from a given list of nested Go test cases.
-func (e *exporter) addStep(allureObj any, testCase gotest.NestedTest, ch chan<- Attachment) {
+func (e *exporter) addStep(allureObj any, testCase gotest.NestedTest, attachments *attachmentStore) {
 	// Iterate through each child test case and create an Allure step with metadata and associated attachments.
 	for _, tc := range testCase.Children {
 		goTest := tc.Value
+		log := e.opts.redactor.Redact(tc.Log)
 		// If the Go test time values are invalid, set them to the current time.
 		if goTest.Stop.Before(goTest.Start) {
 			goTest.Stop = time.Now()
@@ -234,7 +381,7 @@ func (e *exporter) addStep(allureObj any, testCase gotest.NestedTest, ch chan<-
 
 		// Get the test case name and status and create an Allure step with it.
 		name := goTest.Name
-		status := e.convertStatus(goTest, tc.Log)
+		status := e.convertStatus(goTest, log)
 		if len(status) == 0 {
 			// @TODO verbose this
 			continue
@@ -250,38 +397,25 @@ func (e *exporter) addStep(allureObj any, testCase gotest.NestedTest, ch chan<-
 			}
 		}
 		step := allure.Step{
-			Name:        name,
-			Status:      status,
-			Stage:       allure.StageFinished,
-			Start:       goTest.Start.UnixMilli(),
-			Stop:        goTest.Stop.UnixMilli(),
-			Steps:       make([]allure.Step, 0),
-			Attachments: make([]allure.Attachment, 0),
-			Parameters:  make([]allure.Parameter, 0),
+			Name:          name,
+			Status:        status,
+			StatusDetails: e.statusDetails(status, log),
+			Stage:         allure.StageFinished,
+			Start:         goTest.Start.UnixMilli(),
+			Stop:          goTest.Stop.UnixMilli(),
+			Steps:         make([]allure.Step, 0),
+			Attachments:   make([]allure.Attachment, 0),
+			Parameters:    make([]allure.Parameter, 0),
 		}
 
 		// Check if the Go test case has a panic or failure and add the test case log as an attachment to the Allure step.
 		// Also, add a corresponding attachment to the Allure step to enable viewing of the test case log in the report.
 		hasAttachment := e.opts.forceAttachment || goTest.Status == gotest.ActionPanic || goTest.Status == gotest.ActionFail
 		if hasAttachment {
-			source := fmt.Sprintf("%s-attachment.txt", uuid.New().String())
-			mime := "application/json"
-
 			// It also saves attachments from the Go test cases if they are present
-			ch <- Attachment{
-				Name:   goTest.Name,
-				Mime:   mime,
-				Source: source,
-				Body:   tc.Log,
+			if attachment, ok := attachments.add(goTest.Name, "", log); ok {
+				step.Attachments = append(step.Attachments, attachment)
 			}
-
-			step.Attachments = append(
-				step.Attachments, allure.Attachment{
-					Name:   goTest.Name,
-					Source: source,
-					Type:   "application/json",
-				},
-			)
 		}
 
 		switch obj := allureObj.(type) {
@@ -292,44 +426,87 @@ func (e *exporter) addStep(allureObj any, testCase gotest.NestedTest, ch chan<-
 		default:
 		}
 
-		e.addStep(&step, tc, ch)
+		e.addStep(&step, tc, attachments)
 	}
 }
 
 func (e *exporter) defaultLabels(goTest gotest.Test, allureTest *allure.Test) {
+	// The package label groups the tests of the reports, so it is set without the test file metadata too.
+	if goTest.Package != "" {
+		allureTest.Labels = append(allureTest.Labels, allure.Label{Name: "package", Value: goTest.Package})
+	}
+
 	goTestFile, ok := e.files[goTest.Package+goTest.Name]
 	if ok {
-		allureTest.Labels = []allure.Label{
-			{
-				Name:  "package",
-				Value: goTestFile.PackageName,
-			},
-			{
+		allureTest.Labels = append(
+			allureTest.Labels,
+			allure.Label{
 				Name:  "testClass",
 				Value: goTestFile.PackageName + "/" + goTestFile.TestName,
 			},
-			{
+			allure.Label{
 				Name:  "testMethod",
 				Value: goTestFile.TestName,
 			},
-			{
+			allure.Label{
 				Name:  "language",
 				Value: "golang",
 			},
-			{
+			allure.Label{
 				Name:  "go-version",
 				Value: goTestFile.GoVersion,
 			},
-			{
+			allure.Label{
 				Name:  "host",
 				Value: hostname,
 			},
+		)
+
+		if goTestFile.ModulePath != "" {
+			allureTest.Labels = append(allureTest.Labels, allure.Label{Name: "module", Value: goTestFile.ModulePath})
 		}
+
+		for _, owner := range e.opts.codeOwners.Of(filepath.Join(goTestFile.Dir, goTestFile.FileName)) {
+			allureTest.Labels = append(allureTest.Labels, allure.Label{Name: "owner", Value: owner})
+		}
+
+		allureTest.Labels = append(allureTest.Labels, e.git[goTest.Package+goTest.Name].labels()...)
 	}
 
+	allureTest.Labels = append(allureTest.Labels, e.opts.labelRules.Labels(goTest.Package, goTestFile.FileName, goTest.Name)...)
 	allureTest.Labels = append(allureTest.Labels, e.opts.allureLabels...)
 }
 
+// statusDetails describes failed and broken tests with the failure message and the test log as a trace.
+func (*exporter) statusDetails(status string, log []byte) *allure.StatusDetails {
+	if status != allure.StatusFail && status != allure.StatusBroken {
+		return nil
+	}
+
+	return &allure.StatusDetails{
+		Message: failureMessage(log),
+		Trace:   string(log),
+	}
+}
+
+// failureMessage returns the panic line of the log or the first t.Error/t.Fatal line.
+func failureMessage(log []byte) string {
+	lines := strings.Split(string(log), "\n")
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "panic:") {
+			return line
+		}
+	}
+
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); failureLineRegexp.MatchString(line) {
+			return line
+		}
+	}
+
+	return ""
+}
+
 func (*exporter) convertStatus(goTest gotest.Test, log []byte) string {
 	var status string
 	switch goTest.Status {
@@ -342,6 +519,8 @@ func (*exporter) convertStatus(goTest gotest.Test, log []byte) string {
 		}
 
 		status = allure.StatusFail
+	case gotest.ActionPanic:
+		status = allure.StatusBroken
 	case gotest.ActionPass:
 		status = allure.StatusPass
 	default:
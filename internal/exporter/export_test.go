@@ -0,0 +1,358 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/golist"
+	"github.com/robotomize/go-allure/internal/gotest"
+	"github.com/robotomize/go-allure/internal/junit"
+	"github.com/robotomize/go-allure/internal/parser"
+)
+
+type staticReader gotest.Set
+
+func (r staticReader) ReadAll(context.Context) (gotest.Set, error) {
+	return gotest.Set(r), nil
+}
+
+// recordingParser records the import paths it is asked to parse.
+type recordingParser struct {
+	importPaths []string
+	files       []parser.GoTestMethod
+	err         error
+}
+
+func (p *recordingParser) ParseFiles(_ context.Context, importPaths ...string) ([]parser.GoTestMethod, error) {
+	p.importPaths = append(p.importPaths, importPaths...)
+
+	return p.files, p.err
+}
+
+func TestExporter_Read(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		tests    []gotest.NestedTest
+		expected []string
+	}{
+		{
+			name: "test_tested_packages",
+			tests: []gotest.NestedTest{
+				{
+					Value: gotest.Test{Package: "example.com/a", Name: "TestA"},
+					Children: []gotest.NestedTest{
+						{Value: gotest.Test{Package: "example.com/a", Name: "TestA/sub"}},
+					},
+				},
+				{Value: gotest.Test{Package: "example.com/b", Name: "TestB"}},
+				{Value: gotest.Test{Package: "example.com/a", Name: "TestA2"}},
+				{Value: gotest.Test{Name: "TestWithoutPackage"}},
+			},
+			expected: []string{"example.com/a", "example.com/b"},
+		},
+		{
+			name:  "test_no_tests",
+			tests: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				fileParser := &recordingParser{}
+				e := New(fileParser, staticReader{Tests: tc.tests})
+				if err := e.Read(context.Background()); err != nil {
+					t.Fatalf("Read: %v", err)
+				}
+
+				// The parser is asked for each tested package once and never for all packages.
+				if diff := cmp.Diff(tc.expected, fileParser.importPaths); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+func TestExporter_ReadMetadataError(t *testing.T) {
+	t.Parallel()
+
+	unavailable := fmt.Errorf("ImportPathPackages: %w", golist.ErrUnavailable)
+
+	testCases := []struct {
+		name     string
+		err      error
+		degraded bool
+	}{
+		{
+			name:     "test_go_list_unavailable",
+			err:      unavailable,
+			degraded: true,
+		},
+		{
+			name: "test_metadata_file",
+			err:  errors.New("os.ReadFile: open metadata.json: no such file or directory"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				reader := staticReader{
+					Tests: []gotest.NestedTest{{Value: gotest.Test{Package: "a", Name: "TestA", Status: gotest.ActionPass}}},
+				}
+				e := New(&recordingParser{err: tc.err}, reader)
+
+				err := e.Read(context.Background())
+				if !tc.degraded {
+					if !errors.Is(err, tc.err) {
+						t.Fatalf("got: %v, want: %v", err, tc.err)
+					}
+
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("Read: %v", err)
+				}
+
+				// The tests are exported without metadata and the reason is reported.
+				report, err := e.Export()
+				if err != nil {
+					t.Fatalf("Export: %v", err)
+				}
+
+				if !errors.Is(report.MetadataErr, golist.ErrUnavailable) {
+					t.Errorf("got: %v, want: %v", report.MetadataErr, golist.ErrUnavailable)
+				}
+
+				if len(report.Tests) != 1 {
+					t.Errorf("got: %d tests, want: %d", len(report.Tests), 1)
+				}
+			},
+		)
+	}
+}
+
+func TestExporter_ReadCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reader := staticReader{
+		Tests: []gotest.NestedTest{{Value: gotest.Test{Package: "a", Name: "TestA", Status: gotest.ActionPass}}},
+	}
+
+	e := New(&recordingParser{err: fmt.Errorf("listPackages: %w", golist.ErrUnavailable)}, reader)
+	if err := e.Read(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("got: %v, want: %v", err, context.Canceled)
+	}
+}
+
+func TestExporter_ModuleLabel(t *testing.T) {
+	t.Parallel()
+
+	reader := staticReader{
+		Tests: []gotest.NestedTest{
+			{Value: gotest.Test{Package: "example.com/root/api", Name: "TestAPI", Status: gotest.ActionPass}},
+			{Value: gotest.Test{Package: "example.com/gopath", Name: "TestGOPATH", Status: gotest.ActionPass}},
+			{Value: gotest.Test{Package: "example.com/unknown", Name: "TestUnknown", Status: gotest.ActionPass}},
+		},
+	}
+
+	fileParser := &recordingParser{
+		files: []parser.GoTestMethod{
+			{
+				PackageName: "example.com/root/api", TestName: "TestAPI", FileName: "api_test.go",
+				ModulePath: "example.com/root",
+			},
+			{PackageName: "example.com/gopath", TestName: "TestGOPATH", FileName: "gopath_test.go"},
+		},
+	}
+
+	e := New(fileParser, reader)
+	if err := e.Read(context.Background()); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	report, err := e.Export()
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	got := make(map[string][]string, len(report.Tests))
+	for _, test := range report.Tests {
+		got[test.Name] = make([]string, 0)
+		for _, label := range test.Labels {
+			if label.Name == "module" {
+				got[test.Name] = append(got[test.Name], label.Value)
+			}
+		}
+	}
+
+	// Only the tests of a known module get the label.
+	expected := map[string][]string{
+		"TestAPI":     {"example.com/root"},
+		"TestGOPATH":  {},
+		"TestUnknown": {},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestExporter_SourceAttachmentNotInLog(t *testing.T) {
+	t.Parallel()
+
+	source := "func TestFilter(t *testing.T) {\n\tt.Fatal(\"want [3]\")\n}"
+	log := "=== RUN   TestFilter\n    slice_test.go:2: want [3]\n--- FAIL: TestFilter (0.00s)\n"
+	fileParser := &recordingParser{
+		files: []parser.GoTestMethod{
+			{
+				PackageName: "slice", TestName: "TestFilter", FileName: "slice_test.go", TestFileLine: 1,
+				Source: source,
+			},
+		},
+	}
+
+	tests := []gotest.NestedTest{
+		{Value: gotest.Test{Package: "slice", Name: "TestFilter", Status: gotest.ActionFail}, Log: []byte(log)},
+	}
+
+	systemOut, stdout := exportedLogs(t, fileParser, tests, WithSourceAttachment())
+
+	// The source is attached, but only the go test output is the log of the test.
+	if diff := cmp.Diff(log, systemOut["TestFilter"]); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(strings.Split(strings.TrimRight(log, "\n"), "\n"), stdout["TestFilter"]); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// exportedLogs exports the tests and returns the JUnit system-out and the CTRF stdout of each test.
+func exportedLogs(
+	t *testing.T, fileParser FileParser, tests []gotest.NestedTest, opts ...Option,
+) (map[string]string, map[string][]string) {
+	t.Helper()
+
+	e := New(fileParser, staticReader{Tests: tests}, opts...)
+	if err := e.Read(context.Background()); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	report, err := e.Export()
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	dir := t.TempDir()
+	junitPth, ctrfPth := filepath.Join(dir, "junit.xml"), filepath.Join(dir, "ctrf.json")
+
+	ctx := context.Background()
+	w := NewWriter(WriteJUnitTo(junitPth), WriteCTRFTo(ctrfPth))
+	if err = w.WriteReport(ctx, report.Tests); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+
+	if err = w.WriteAttachments(ctx, report.Attachments); err != nil {
+		t.Fatalf("WriteAttachments: %v", err)
+	}
+
+	if err = w.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	b, err := os.ReadFile(junitPth)
+	if err != nil {
+		t.Fatalf("os.ReadFile: %v", err)
+	}
+
+	var suites junit.TestSuites
+	if err = xml.Unmarshal(b, &suites); err != nil {
+		t.Fatalf("xml.Unmarshal: %v", err)
+	}
+
+	systemOut := make(map[string]string)
+	for _, suite := range suites.Suites {
+		for _, tc := range suite.TestCases {
+			systemOut[tc.Name] = tc.SystemOut.String()
+		}
+	}
+
+	if b, err = os.ReadFile(ctrfPth); err != nil {
+		t.Fatalf("os.ReadFile: %v", err)
+	}
+
+	var ctrf ctrfReport
+	if err = json.Unmarshal(b, &ctrf); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	stdout := make(map[string][]string)
+	for _, tc := range ctrf.Results.Tests {
+		stdout[tc.Name] = tc.Stdout
+	}
+
+	return systemOut, stdout
+}
+
+func TestExporter_PackageLabel(t *testing.T) {
+	t.Parallel()
+
+	reader := staticReader{
+		Tests: []gotest.NestedTest{
+			{Value: gotest.Test{Package: "example.com/a", Name: "TestParsed", Status: gotest.ActionPass}},
+			{Value: gotest.Test{Package: "example.com/b", Name: "TestNotParsed", Status: gotest.ActionPass}},
+			{Value: gotest.Test{Name: "TestWithoutPackage", Status: gotest.ActionPass}},
+		},
+	}
+
+	fileParser := &recordingParser{
+		files: []parser.GoTestMethod{{PackageName: "example.com/a", TestName: "TestParsed", FileName: "a_test.go"}},
+	}
+
+	e := New(fileParser, reader)
+	if err := e.Read(context.Background()); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	report, err := e.Export()
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	got := make(map[string]string, len(report.Tests))
+	for _, test := range report.Tests {
+		got[test.Name] = testPackage(test)
+	}
+
+	// The tests are grouped by the package of the go test events, the parser metadata is not required.
+	expected := map[string]string{
+		"TestParsed":         "example.com/a",
+		"TestNotParsed":      "example.com/b",
+		"TestWithoutPackage": defaultPackageName,
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,138 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/robotomize/go-allure/internal/allure"
+	"github.com/robotomize/go-allure/internal/parser"
+)
+
+const (
+	// maxFailureLocations limits the number of excerpts attached to a single test.
+	maxFailureLocations = 3
+	// excerptContext is the number of lines shown before and after the failing line.
+	excerptContext = 3
+)
+
+var (
+	// failureLocationRegexp matches t.Error/t.Fatal lines, the file name is relative to the package directory.
+	failureLocationRegexp = regexp.MustCompile(`^\s*(\S+\.go):(\d+): `)
+	// panicFrameRegexp matches the file lines of panic stack frames.
+	panicFrameRegexp = regexp.MustCompile(`^\s+(/\S+\.go):(\d+)(?:\s|$)`)
+)
+
+type location struct {
+	pth  string
+	line int
+}
+
+// failureLocations returns the source lines referenced by the log, which are inside the module of the test.
+func failureLocations(log []byte, file parser.GoTestMethod) []location {
+	root := file.ModuleDir
+	if root == "" {
+		root = file.Dir
+	}
+
+	var locations []location
+	seen := make(map[location]struct{})
+	for _, line := range bytes.Split(log, []byte("\n")) {
+		m := failureLocationRegexp.FindSubmatch(line)
+		if m == nil {
+			m = panicFrameRegexp.FindSubmatch(line)
+		}
+
+		if m == nil {
+			continue
+		}
+
+		pth := string(m[1])
+		if !filepath.IsAbs(pth) {
+			pth = filepath.Join(file.Dir, pth)
+		}
+
+		// Frames of the runtime, the testing package and dependencies are skipped.
+		if rel, err := filepath.Rel(root, pth); err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		n, _ := strconv.Atoi(string(m[2]))
+		loc := location{pth: pth, line: n}
+		if _, ok := seen[loc]; ok {
+			continue
+		}
+
+		seen[loc] = struct{}{}
+		if locations = append(locations, loc); len(locations) == maxFailureLocations {
+			break
+		}
+	}
+
+	return locations
+}
+
+// failureSources attaches source excerpts of the failure locations and links them to the VCS web UI.
+func (e *exporter) failureSources(test *allure.Test, log []byte, file parser.GoTestMethod, store *attachmentStore) {
+	for _, loc := range failureLocations(log, file) {
+		name := fmt.Sprintf("%s:%d", filepath.Base(loc.pth), loc.line)
+
+		excerpt, ok := e.excerpt(loc)
+		if !ok {
+			continue
+		}
+
+		if attachment, ok := store.add(name, MimeTextPlain, excerpt); ok {
+			test.Attachments = append(test.Attachments, attachment)
+		}
+
+		if e.opts.sourceLinks != nil {
+			if url, ok := e.opts.sourceLinks.url(loc.pth, loc.line); ok {
+				test.Links = append(test.Links, allure.Link{Name: name, URL: url, Type: "link"})
+			}
+		}
+	}
+}
+
+// excerpt returns the numbered lines around the location, the failing line is marked with ">".
+func (e *exporter) excerpt(loc location) ([]byte, bool) {
+	lines, ok := e.sources[loc.pth]
+	if !ok {
+		b, err := os.ReadFile(loc.pth)
+		if err == nil {
+			lines = strings.Split(string(b), "\n")
+		}
+
+		e.sources[loc.pth] = lines
+	}
+
+	if loc.line < 1 || loc.line > len(lines) {
+		return nil, false
+	}
+
+	from, to := loc.line-excerptContext, loc.line+excerptContext
+	if from < 1 {
+		from = 1
+	}
+
+	if to > len(lines) {
+		to = len(lines)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0))
+	width := len(strconv.Itoa(to))
+	for n := from; n <= to; n++ {
+		marker := " "
+		if n == loc.line {
+			marker = ">"
+		}
+
+		_, _ = fmt.Fprintf(buf, "%s %*d | %s\n", marker, width, n, lines[n-1])
+	}
+
+	return buf.Bytes(), true
+}
@@ -0,0 +1,91 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/gotest"
+	"github.com/robotomize/go-allure/internal/parser"
+)
+
+func TestFailureLocations(t *testing.T) {
+	t.Parallel()
+
+	file := parser.GoTestMethod{Dir: "/repo/internal/slice", ModuleDir: "/repo"}
+
+	testCases := []struct {
+		name     string
+		log      string
+		expected []location
+	}{
+		{
+			name:     "test_error_line",
+			log:      "=== RUN   TestFilter\n    slice_test.go:96: got: [3 4], want: [3]\n    slice_test.go:96: got: [3 4], want: [3]\n",
+			expected: []location{{pth: "/repo/internal/slice/slice_test.go", line: 96}},
+		},
+		{
+			name: "test_panic_frames",
+			log: "panic: boom [recovered]\n\ngoroutine 7 [running]:\ntesting.tRunner.func1()\n" +
+				"\t/usr/local/go/src/testing/testing.go:1545 +0x238\n" +
+				"github.com/acme/repo/internal/slice.Filter(...)\n\t/repo/internal/slice/slice.go:12 +0x1c\n",
+			expected: []location{{pth: "/repo/internal/slice/slice.go", line: 12}},
+		},
+		{
+			name: "test_no_locations",
+			log:  "--- FAIL: TestFilter (0.00s)\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				got := failureLocations([]byte(tc.log), file)
+				if diff := cmp.Diff(tc.expected, got, cmp.AllowUnexported(location{})); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+func TestExporter_FailureSourcesNotInLog(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := "package slice\n\nfunc TestFilter(t *testing.T) {\n\tt.Fatal(\"want [3]\")\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "slice_test.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	log := "=== RUN   TestFilter\n    slice_test.go:4: want [3]\n--- FAIL: TestFilter (0.00s)\n"
+	fileParser := &recordingParser{
+		files: []parser.GoTestMethod{
+			{
+				PackageName: "slice", TestName: "TestFilter", FileName: "slice_test.go", TestFileLine: 3,
+				Dir: dir, ModuleDir: dir,
+			},
+		},
+	}
+
+	tests := []gotest.NestedTest{
+		{Value: gotest.Test{Package: "slice", Name: "TestFilter", Status: gotest.ActionFail}, Log: []byte(log)},
+	}
+
+	systemOut, stdout := exportedLogs(t, fileParser, tests, WithFailureSources())
+
+	// The excerpt of slice_test.go:4 is attached, but it is not a part of the test output.
+	if diff := cmp.Diff(log, systemOut["TestFilter"]); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(strings.Split(strings.TrimRight(log, "\n"), "\n"), stdout["TestFilter"]); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
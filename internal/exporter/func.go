@@ -3,6 +3,9 @@ package exporter
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"time"
 )
 
 // mkdir checks if the provided path exists and creates it if it does not.
@@ -17,3 +20,174 @@ func mkdir(pth string) error {
 
 	return nil
 }
+
+// mkdirRun creates the run subdirectory and its parents, it fails if the run subdirectory already exists.
+func mkdirRun(pth string) error {
+	if err := mkdir(filepath.Dir(pth)); err != nil {
+		return err
+	}
+
+	if err := os.Mkdir(pth, os.ModePerm); err != nil {
+		return fmt.Errorf("os.Mkdir: %w", err)
+	}
+
+	return nil
+}
+
+// exists reports whether the path exists.
+func exists(pth string) bool {
+	_, err := os.Stat(pth)
+	return err == nil
+}
+
+// writeFile atomically writes the body to the path via a temporary file renamed into place.
+func writeFile(pth string, body []byte, fsync bool) (err error) {
+	dir, name := filepath.Split(pth)
+	file, err := os.CreateTemp(dir, "."+name+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+
+	// Remove the temporary file if it was not renamed.
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}
+	}()
+
+	if _, err = file.Write(body); err != nil {
+		return fmt.Errorf("file Write: %w", err)
+	}
+
+	if fsync {
+		if err = file.Sync(); err != nil {
+			return fmt.Errorf("file Sync: %w", err)
+		}
+	}
+
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("file Close: %w", err)
+	}
+
+	if err = os.Chmod(file.Name(), 0o644); err != nil {
+		return fmt.Errorf("os.Chmod: %w", err)
+	}
+
+	if err = os.Rename(file.Name(), pth); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+
+	return nil
+}
+
+// allureHistoryDir is kept by clean, allure uses it to build trends between runs.
+const allureHistoryDir = "history"
+
+// RunSubdirLayout names the timestamped run subdirectories of the output path. The milliseconds keep the runs
+// of the same second apart, a run never writes into an existing subdirectory.
+const RunSubdirLayout = "20060102-150405.000"
+
+// runSubdirSecondsLayout parses the run subdirectory names with or without the fraction of a second.
+const runSubdirSecondsLayout = "20060102-150405"
+
+var allureArtifactFiles = map[string]struct{}{
+	environmentFile:   {},
+	"categories.json": {},
+	"executor.json":   {},
+}
+
+var (
+	// allureArtifactRegexp matches <uuid>-result.json, <uuid>-container.json and <uuid>-attachment.<ext>.
+	allureArtifactRegexp = regexp.MustCompile(
+		`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}-` +
+			`(result\.json|container\.json|attachment(\.[0-9A-Za-z]+)?)$`,
+	)
+	// tempFileRegexp matches the temporary files left by an interrupted atomic write.
+	tempFileRegexp = regexp.MustCompile(`^\.(.+)\.tmp-[0-9]+$`)
+)
+
+// isAllureArtifact reports whether the file name is a file produced for an allure report.
+func isAllureArtifact(name string) bool {
+	if m := tempFileRegexp.FindStringSubmatch(name); m != nil {
+		name = m[1]
+	}
+
+	if _, ok := allureArtifactFiles[name]; ok {
+		return true
+	}
+
+	return allureArtifactRegexp.MatchString(name)
+}
+
+// isRunSubdir reports whether the directory name is a run subdirectory written with --output-run-subdir.
+func isRunSubdir(name string) bool {
+	_, err := time.Parse(runSubdirSecondsLayout, name)
+	return err == nil
+}
+
+// clean removes allure artifacts and the run subdirectories of previous runs from the directory.
+// It refuses to touch a directory containing anything else to avoid wiping a wrongly passed output path.
+func clean(pth string) error {
+	entries, err := os.ReadDir(pth)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("os.ReadDir: %w", err)
+	}
+
+	// Check everything before removing anything.
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case entry.IsDir() && name == allureHistoryDir:
+		case entry.IsDir() && isRunSubdir(name):
+			if err = checkArtifacts(filepath.Join(pth, name)); err != nil {
+				return fmt.Errorf("refusing to clean %s: %w", pth, err)
+			}
+		case !entry.IsDir() && isAllureArtifact(name):
+		default:
+			return fmt.Errorf("refusing to clean %s: %s is not an allure artifact", pth, name)
+		}
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case entry.IsDir() && name == allureHistoryDir:
+			continue
+		case entry.IsDir():
+			err = os.RemoveAll(filepath.Join(pth, name))
+		default:
+			err = os.Remove(filepath.Join(pth, name))
+		}
+
+		if err != nil {
+			return fmt.Errorf("os.Remove: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkArtifacts returns an error if the run subdirectory contains anything but allure artifacts.
+func checkArtifacts(pth string) error {
+	entries, err := os.ReadDir(pth)
+	if err != nil {
+		return fmt.Errorf("os.ReadDir: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() == allureHistoryDir {
+			continue
+		}
+
+		if entry.IsDir() || !isAllureArtifact(entry.Name()) {
+			return fmt.Errorf("%s is not an allure artifact", filepath.Join(filepath.Base(pth), entry.Name()))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package exporter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testUUID = "0b1e7a4c-9a1d-4d7e-8f3b-2c5d6e7f8a9b"
+
+func TestIsAllureArtifact(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		file     string
+		expected bool
+	}{
+		{name: "test_result", file: testUUID + "-result.json", expected: true},
+		{name: "test_container", file: testUUID + "-container.json", expected: true},
+		{name: "test_attachment", file: testUUID + "-attachment.txt", expected: true},
+		{name: "test_attachment_without_extension", file: testUUID + "-attachment", expected: true},
+		{name: "test_environment", file: "environment.properties", expected: true},
+		{name: "test_categories", file: "categories.json", expected: true},
+		{name: "test_executor", file: "executor.json", expected: true},
+		{name: "test_temporary_result", file: "." + testUUID + "-result.json.tmp-123456", expected: true},
+		{name: "test_temporary_environment", file: ".environment.properties.tmp-42", expected: true},
+		{name: "test_user_result", file: "my-result.json"},
+		{name: "test_user_attachment", file: "design-attachment-notes.md"},
+		{name: "test_attachment_suffix", file: testUUID + "-attachment.txt.bak"},
+		{name: "test_short_uuid", file: "0b1e7a4c-result.json"},
+		{name: "test_user_temporary", file: ".notes.md.tmp-1"},
+		{name: "test_temporary_without_digits", file: "." + testUUID + "-result.json.tmp-"},
+		{name: "test_readme", file: "README.md"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				if got := isAllureArtifact(tc.file); got != tc.expected {
+					t.Errorf("got: %v, want: %v", got, tc.expected)
+				}
+			},
+		)
+	}
+}
+
+func TestClean(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		files    []string
+		expected []string
+		err      bool
+	}{
+		{
+			name: "test_artifacts",
+			files: []string{
+				testUUID + "-result.json",
+				testUUID + "-container.json",
+				testUUID + "-attachment.txt",
+				"environment.properties",
+				"." + testUUID + "-result.json.tmp-1",
+			},
+			expected: []string{},
+		},
+		{
+			name:     "test_history_kept",
+			files:    []string{testUUID + "-result.json", "history/history-trend.json"},
+			expected: []string{"history", "history/history-trend.json"},
+		},
+		{
+			name: "test_run_subdirs",
+			files: []string{
+				"20240102-150405/" + testUUID + "-result.json",
+				"20240102-150405/" + testUUID + "-attachment.txt",
+				"20240103-090000/environment.properties",
+				"20240104-120000.250/" + testUUID + "-result.json",
+				testUUID + "-result.json",
+			},
+			expected: []string{},
+		},
+		{
+			name:     "test_user_file",
+			files:    []string{testUUID + "-result.json", "notes-attachment.md"},
+			expected: []string{testUUID + "-result.json", "notes-attachment.md"},
+			err:      true,
+		},
+		{
+			name:     "test_user_dir",
+			files:    []string{testUUID + "-result.json", "src/main.go"},
+			expected: []string{testUUID + "-result.json", "src", "src/main.go"},
+			err:      true,
+		},
+		{
+			name: "test_user_file_in_run_subdir",
+			files: []string{
+				"20240102-150405/" + testUUID + "-result.json",
+				"20240103-090000/report.pdf",
+			},
+			expected: []string{
+				"20240102-150405",
+				"20240102-150405/" + testUUID + "-result.json",
+				"20240103-090000",
+				"20240103-090000/report.pdf",
+			},
+			err: true,
+		},
+		{
+			name:     "test_missing_dir",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				dir := filepath.Join(t.TempDir(), "allure-results")
+				for _, file := range tc.files {
+					pth := filepath.Join(dir, filepath.FromSlash(file))
+					if err := os.MkdirAll(filepath.Dir(pth), 0o755); err != nil {
+						t.Fatalf("os.MkdirAll: %v", err)
+					}
+
+					if err := os.WriteFile(pth, []byte("{}"), 0o644); err != nil {
+						t.Fatalf("os.WriteFile: %v", err)
+					}
+				}
+
+				err := clean(dir)
+				if (err != nil) != tc.err {
+					t.Fatalf("got: %v, want error: %v", err, tc.err)
+				}
+
+				if diff := cmp.Diff(tc.expected, treeEntries(t, dir)); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+func TestWriter_CleanRunSubdir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	previous := filepath.Join(dir, "20240102-150405", testUUID+"-result.json")
+	if err := os.MkdirAll(filepath.Dir(previous), 0o755); err != nil {
+		t.Fatalf("os.MkdirAll: %v", err)
+	}
+
+	if err := os.WriteFile(previous, []byte("{}"), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	w := NewWriter(WriteToRunSubdir(dir, "20240103-090000"), WithClean())
+	if err := w.WriteEnvironment(context.Background(), map[string]string{"go.version": "go1.20"}); err != nil {
+		t.Fatalf("WriteEnvironment: %v", err)
+	}
+
+	expected := []string{"20240103-090000", "20240103-090000/" + environmentFile}
+	if diff := cmp.Diff(expected, treeEntries(t, dir)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestWriter_RunSubdirExists(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	run := time.Date(2024, 1, 3, 9, 0, 0, 250*int(time.Millisecond), time.UTC).Format(RunSubdirLayout)
+	if !isRunSubdir(run) {
+		t.Fatalf("got: %s is not a run subdirectory, want: a run subdirectory", run)
+	}
+
+	ctx := context.Background()
+	env := map[string]string{"go.version": "go1.20"}
+	if err := NewWriter(WriteToRunSubdir(dir, run)).WriteEnvironment(ctx, env); err != nil {
+		t.Fatalf("WriteEnvironment: %v", err)
+	}
+
+	// Another run of the same time neither writes into the subdirectory nor cleans it.
+	if err := NewWriter(WriteToRunSubdir(dir, run), WithClean()).WriteEnvironment(ctx, env); err == nil {
+		t.Fatalf("got: nil, want: existing run subdirectory error")
+	}
+
+	expected := []string{run, run + "/" + environmentFile}
+	if diff := cmp.Diff(expected, treeEntries(t, dir)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// treeEntries returns the sorted slash separated paths of all files and directories under the directory.
+func treeEntries(t *testing.T, dir string) []string {
+	t.Helper()
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	entries := make([]string, 0)
+	err := filepath.WalkDir(
+		dir, func(pth string, _ os.DirEntry, err error) error {
+			if err != nil || pth == dir {
+				return err
+			}
+
+			rel, err := filepath.Rel(dir, pth)
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, filepath.ToSlash(rel))
+
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("filepath.WalkDir: %v", err)
+	}
+
+	sort.Strings(entries)
+
+	return entries
+}
@@ -0,0 +1,183 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/robotomize/go-allure/internal/allure"
+	"github.com/robotomize/go-allure/internal/git"
+	"github.com/robotomize/go-allure/internal/parser"
+)
+
+// gitMetadata is the repository and the author of the test function found for a test.
+type gitMetadata struct {
+	repo   *git.Repository
+	author string
+}
+
+// readGit opens the repositories of the exported tests once per work tree and blames their test functions.
+// Tests outside a git work tree are left without git metadata.
+func (e *exporter) readGit(ctx context.Context) error {
+	// Only the test functions of the exported tests are blamed, not every test of the parsed packages.
+	files := make(map[string]parser.GoTestMethod)
+	for _, tc := range e.tests {
+		key := tc.Value.Package + tc.Value.Name
+		if file, ok := e.files[key]; ok {
+			files[key] = file
+		}
+	}
+
+	// Modules of the same work tree share the repository and its blame cache.
+	repos := make(map[string]*git.Repository)
+	dirs := make(map[string]*git.Repository)
+	for _, file := range files {
+		dir := file.ModuleDir
+		if dir == "" {
+			dir = file.Dir
+		}
+
+		if _, ok := dirs[dir]; ok || dir == "" {
+			continue
+		}
+
+		root, err := git.Root(ctx, dir)
+		if err != nil {
+			if errors.Is(err, git.ErrNotRepository) {
+				dirs[dir] = nil
+				continue
+			}
+
+			return fmt.Errorf("git.Root: %w", err)
+		}
+
+		repo, ok := repos[root]
+		if !ok {
+			if repo, err = git.Open(ctx, root); err != nil && !errors.Is(err, git.ErrNotRepository) {
+				return fmt.Errorf("git.Open: %w", err)
+			}
+
+			repos[root] = repo
+		}
+
+		dirs[dir] = repo
+	}
+
+	// Use errgroup to limit the number of git processes.
+	wg, grpCtx := errgroup.WithContext(ctx)
+	wg.SetLimit(runtime.NumCPU())
+
+	ch := make(chan struct {
+		key      string
+		metadata gitMetadata
+	})
+	closeCh := make(chan struct{})
+
+	go func() {
+		defer close(closeCh)
+
+		for m := range ch {
+			e.git[m.key] = m.metadata
+		}
+	}()
+
+OuterLoop:
+	for key, file := range files {
+		key, file := key, file
+
+		dir := file.ModuleDir
+		if dir == "" {
+			dir = file.Dir
+		}
+
+		repo := dirs[dir]
+		if repo == nil {
+			continue
+		}
+
+		select {
+		case <-grpCtx.Done():
+			break OuterLoop
+		default:
+		}
+
+		wg.Go(
+			func() error {
+				// Untracked files cannot be blamed, their tests only get the repository metadata.
+				author, _ := repo.Author(grpCtx, filepath.Join(file.Dir, file.FileName), file.TestFileLine)
+
+				ch <- struct {
+					key      string
+					metadata gitMetadata
+				}{key: key, metadata: gitMetadata{repo: repo, author: author}}
+
+				return nil
+			},
+		)
+	}
+
+	err := wg.Wait()
+	close(ch)
+	<-closeCh
+
+	if err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+// labels returns the commit, branch, repository URL and author labels of the test.
+func (m gitMetadata) labels() []allure.Label {
+	if m.repo == nil {
+		return nil
+	}
+
+	var labels []allure.Label
+	for _, l := range []allure.Label{
+		{Name: "git.commit", Value: m.repo.Commit},
+		{Name: "git.branch", Value: m.repo.Branch},
+		{Name: "git.url", Value: m.repo.URL},
+		{Name: "git.author", Value: m.author},
+	} {
+		if l.Value != "" {
+			labels = append(labels, l)
+		}
+	}
+
+	return labels
+}
+
+// gitEnvironment returns the environment entries of the repositories, the first repository by path wins.
+func (e *exporter) gitEnvironment() map[string]string {
+	repos := make(map[string]*git.Repository)
+	roots := make([]string, 0)
+	for _, m := range e.git {
+		if _, ok := repos[m.repo.Root]; !ok {
+			repos[m.repo.Root] = m.repo
+			roots = append(roots, m.repo.Root)
+		}
+	}
+
+	if len(roots) == 0 {
+		return nil
+	}
+
+	sort.Strings(roots)
+
+	env := make(map[string]string)
+	for _, root := range roots {
+		for key, value := range repos[root].Environment() {
+			if _, ok := env[key]; !ok {
+				env[key] = value
+			}
+		}
+	}
+
+	return env
+}
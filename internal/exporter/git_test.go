@@ -0,0 +1,117 @@
+package exporter
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/gotest"
+	"github.com/robotomize/go-allure/internal/parser"
+)
+
+func TestExporter_ReadGit(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("filepath.EvalSymlinks: %v", err)
+	}
+
+	// Two modules of the same repository and a directory outside of it.
+	for _, pth := range []string{"a/a_test.go", "b/b_test.go"} {
+		full := filepath.Join(dir, "repo", filepath.FromSlash(pth))
+		if err = os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("os.MkdirAll: %v", err)
+		}
+
+		if err = os.WriteFile(full, []byte("package a\n\nfunc TestA(t *testing.T) {}\n"), 0o644); err != nil {
+			t.Fatalf("os.WriteFile: %v", err)
+		}
+	}
+
+	outside := filepath.Join(dir, "outside")
+	if err = os.MkdirAll(outside, 0o755); err != nil {
+		t.Fatalf("os.MkdirAll: %v", err)
+	}
+
+	repo := filepath.Join(dir, "repo")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=Ann Lee", "-c", "user.email=ann@example.com", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "GIT_CONFIG_GLOBAL="+os.DevNull)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	e := &exporter{
+		tests: []gotest.NestedTest{
+			{Value: gotest.Test{Package: "a", Name: "TestA"}},
+			{Value: gotest.Test{Package: "b", Name: "TestB"}},
+			{Value: gotest.Test{Package: "outside", Name: "TestOutside"}},
+		},
+		files: map[string]parser.GoTestMethod{
+			"aTestA": {
+				PackageName: "a", TestName: "TestA", FileName: "a_test.go", TestFileLine: 3,
+				Dir: filepath.Join(repo, "a"), ModuleDir: filepath.Join(repo, "a"),
+			},
+			"aTestNotRun": {
+				PackageName: "a", TestName: "TestNotRun", FileName: "a_test.go", TestFileLine: 5,
+				Dir: filepath.Join(repo, "a"), ModuleDir: filepath.Join(repo, "a"),
+			},
+			"bTestB": {
+				PackageName: "b", TestName: "TestB", FileName: "b_test.go", TestFileLine: 3,
+				Dir: filepath.Join(repo, "b"), ModuleDir: filepath.Join(repo, "b"),
+			},
+			"outsideTestOutside": {
+				PackageName: "outside", TestName: "TestOutside", FileName: "outside_test.go", TestFileLine: 3,
+				Dir: outside, ModuleDir: outside,
+			},
+		},
+		git: make(map[string]gitMetadata),
+	}
+
+	if err = e.readGit(context.Background()); err != nil {
+		t.Fatalf("readGit: %v", err)
+	}
+
+	keys := make([]string, 0, len(e.git))
+	for key := range e.git {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	// Only the exported tests inside the work tree are blamed.
+	if diff := cmp.Diff([]string{"aTestA", "bTestB"}, keys); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// Both modules share the repository of the work tree.
+	if e.git["aTestA"].repo != e.git["bTestB"].repo {
+		t.Errorf("got: separate repositories, want: a repository per work tree")
+	}
+
+	for _, key := range keys {
+		if diff := cmp.Diff(repo, e.git[key].repo.Root); diff != "" {
+			t.Errorf("mismatch (-want, +got):\n%s", diff)
+		}
+
+		if diff := cmp.Diff("Ann Lee", e.git[key].author); diff != "" {
+			t.Errorf("mismatch (-want, +got):\n%s", diff)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package exporter
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+const htmlReportTitle = "Go test report"
+
+//go:embed templates/report.html
+var htmlReportTmpl string
+
+var htmlTemplate = template.Must(template.New("report").Parse(htmlReportTmpl))
+
+type htmlReport struct {
+	Title     string
+	Generated string
+	Total     int
+	Passed    int
+	Failed    int
+	Broken    int
+	Skipped   int
+	Packages  []*htmlPackage
+}
+
+type htmlPackage struct {
+	Name    string
+	Passed  int
+	Failed  int
+	Broken  int
+	Skipped int
+	Tests   []htmlTest
+}
+
+type htmlTest struct {
+	Name        string
+	Description string
+	Status      string
+	Duration    string
+	Message     string
+	Log         string
+	Steps       []htmlStep
+}
+
+type htmlStep struct {
+	Name     string
+	Status   string
+	Duration string
+	Log      string
+	Steps    []htmlStep
+}
+
+// WriteHTML renders the report into a single self-contained HTML page.
+func WriteHTML(w io.Writer, report Report) error {
+	logs := make(map[string][]byte, len(report.Attachments))
+	for _, attachment := range report.Attachments {
+		logs[attachment.Source] = attachment.Body
+	}
+
+	return writeHTML(w, report.Tests, logs)
+}
+
+// writeHTML writes the collected tests as an HTML report to the given path.
+func (o *writer) writeHTML() error {
+	dir, _ := filepath.Split(o.htmlPth)
+	if dir != "" {
+		if err := mkdir(dir); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(o.htmlPth, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile: %w", err)
+	}
+
+	defer file.Close()
+
+	return writeHTML(file, o.tests, o.logs)
+}
+
+func writeHTML(w io.Writer, tests []allure.Test, logs map[string][]byte) error {
+	report := htmlReport{
+		Title:     htmlReportTitle,
+		Generated: time.Now().Format(time.RFC1123),
+		Total:     len(tests),
+	}
+
+	// Group tests into packages and count statuses.
+	packages := make(map[string]*htmlPackage)
+	for _, tc := range tests {
+		name := testPackage(tc)
+		pkg, ok := packages[name]
+		if !ok {
+			pkg = &htmlPackage{Name: name}
+			packages[name] = pkg
+			report.Packages = append(report.Packages, pkg)
+		}
+
+		switch tc.Status {
+		case allure.StatusPass:
+			pkg.Passed++
+			report.Passed++
+		case allure.StatusFail:
+			pkg.Failed++
+			report.Failed++
+		case allure.StatusBroken:
+			pkg.Broken++
+			report.Broken++
+		case allure.StatusSkip:
+			pkg.Skipped++
+			report.Skipped++
+		default:
+		}
+
+		test := htmlTest{
+			Name:        tc.Name,
+			Description: tc.Description,
+			Status:      tc.Status,
+			Duration:    summaryDuration(tc.Stop - tc.Start),
+			Log:         attachmentsLog(tc.Name, tc.Attachments, logs),
+			Steps:       htmlSteps(tc.Steps, logs),
+		}
+
+		if details := tc.StatusDetails; details != nil {
+			test.Message = details.Message
+		}
+
+		pkg.Tests = append(pkg.Tests, test)
+	}
+
+	sort.Slice(
+		report.Packages, func(i, j int) bool {
+			return report.Packages[i].Name < report.Packages[j].Name
+		},
+	)
+
+	if err := htmlTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("template Execute: %w", err)
+	}
+
+	return nil
+}
+
+func htmlSteps(steps []allure.Step, logs map[string][]byte) []htmlStep {
+	result := make([]htmlStep, 0, len(steps))
+	for _, step := range steps {
+		result = append(
+			result, htmlStep{
+				Name:     step.Name,
+				Status:   step.Status,
+				Duration: summaryDuration(step.Stop - step.Start),
+				Log:      attachmentsLog(step.Name, step.Attachments, logs),
+				Steps:    htmlSteps(step.Steps, logs),
+			},
+		)
+	}
+
+	return result
+}
+
+// attachmentsLog returns the go test output of the test or step with the given name. The output attachment is
+// named after the test, the source and failure excerpt attachments are not a part of the log.
+func attachmentsLog(name string, attachments []allure.Attachment, logs map[string][]byte) string {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	for _, attachment := range attachments {
+		if attachment.Name == name {
+			buf.Write(logs[attachment.Source])
+		}
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,89 @@
+package exporter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+func TestWriteHTML(t *testing.T) {
+	t.Parallel()
+
+	report := Report{
+		Tests: []allure.Test{
+			{
+				Name:          `TestFilter/<script>alert("name")</script>`,
+				Description:   "<b>description</b>",
+				Status:        allure.StatusFail,
+				Labels:        []allure.Label{{Name: "package", Value: "<i>slice</i>"}},
+				StatusDetails: &allure.StatusDetails{Message: "want <nil> & got <img src=x onerror=alert(1)>"},
+				Attachments: []allure.Attachment{
+					{Name: `TestFilter/<script>alert("name")</script>`, Source: "filter-attachment.txt"},
+				},
+				Steps: []allure.Step{
+					{Name: "TestFilter/<u>step</u>", Status: allure.StatusPass},
+				},
+			},
+			{Name: "TestMap", Status: allure.StatusPass, Labels: []allure.Label{{Name: "package", Value: "maps"}}},
+		},
+		Attachments: []Attachment{
+			{Source: "filter-attachment.txt", Body: []byte("=== RUN   TestFilter\n</pre><script>log</script>\n")},
+		},
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := WriteHTML(buf, report); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+
+	got := buf.String()
+	for _, raw := range []string{
+		"<script>alert", "<b>description</b>", "<i>slice</i>", "<img src=x", "</pre><script>log", "<u>step</u>",
+	} {
+		if strings.Contains(got, raw) {
+			t.Errorf("unescaped %q in the report", raw)
+		}
+	}
+
+	for _, escaped := range []string{
+		"TestFilter/&lt;script&gt;alert(&#34;name&#34;)&lt;/script&gt;",
+		"&lt;b&gt;description&lt;/b&gt;",
+		"&lt;i&gt;slice&lt;/i&gt;",
+		"want &lt;nil&gt; &amp; got &lt;img src=x onerror=alert(1)&gt;",
+		"&lt;/pre&gt;&lt;script&gt;log&lt;/script&gt;",
+		"TestFilter/&lt;u&gt;step&lt;/u&gt;",
+		"TestMap",
+	} {
+		if !strings.Contains(got, escaped) {
+			t.Errorf("missing %q in the report", escaped)
+		}
+	}
+}
+
+func TestWriter_WriteHTML(t *testing.T) {
+	t.Parallel()
+
+	pth := filepath.Join(t.TempDir(), "html", "index.html")
+	o := &writer{
+		htmlPth: pth,
+		tests:   []allure.Test{{Name: "TestFilter", Status: allure.StatusPass}},
+		logs:    make(map[string][]byte),
+	}
+
+	if err := o.writeHTML(); err != nil {
+		t.Fatalf("writeHTML: %v", err)
+	}
+
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		t.Fatalf("os.ReadFile: %v", err)
+	}
+
+	if !bytes.Contains(b, []byte("TestFilter")) {
+		t.Errorf("missing the test in the report")
+	}
+}
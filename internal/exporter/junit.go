@@ -0,0 +1,135 @@
+package exporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/robotomize/go-allure/internal/allure"
+	"github.com/robotomize/go-allure/internal/junit"
+)
+
+const defaultPackageName = "default"
+
+// writeJUnit writes the collected tests as a JUnit XML report with a testsuite per package.
+func (o *writer) writeJUnit() error {
+	dir, _ := filepath.Split(o.junitPth)
+	if dir != "" {
+		if err := mkdir(dir); err != nil {
+			return err
+		}
+	}
+
+	b, err := xml.MarshalIndent(o.junitSuites(), "", "    ")
+	if err != nil {
+		return fmt.Errorf("xml.MarshalIndent: %w", err)
+	}
+
+	if err = os.WriteFile(o.junitPth, append([]byte(xml.Header), b...), 0o644); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+
+	return nil
+}
+
+func (o *writer) junitSuites() junit.TestSuites {
+	var suites junit.TestSuites
+
+	idx := make(map[string]int)
+	durations := make([]int64, 0)
+	for _, tc := range o.tests {
+		pkg := testPackage(tc)
+
+		pos, ok := idx[pkg]
+		if !ok {
+			pos = len(suites.Suites)
+			idx[pkg] = pos
+			suites.Suites = append(
+				suites.Suites, junit.TestSuite{
+					Name:      pkg,
+					Timestamp: time.UnixMilli(tc.Start).Format(time.RFC3339),
+					Hostname:  hostname,
+				},
+			)
+			durations = append(durations, 0)
+		}
+
+		suite := &suites.Suites[pos]
+		testCase := junit.TestCase{
+			Name:      tc.Name,
+			ClassName: pkg,
+			Time:      junitTime(tc.Stop - tc.Start),
+		}
+
+		result := &junit.Result{}
+		if details := tc.StatusDetails; details != nil {
+			result.Message = details.Message
+			result.Text = details.Trace
+		}
+
+		switch tc.Status {
+		case allure.StatusFail:
+			result.Type = "failure"
+			testCase.Failure = result
+			suite.Failures++
+		case allure.StatusBroken:
+			result.Type = "error"
+			testCase.Error = result
+			suite.Errors++
+		case allure.StatusSkip:
+			testCase.Skipped = &junit.Result{}
+			suite.Skipped++
+		default:
+		}
+
+		// The test log is taken from the attachments of the test.
+		testCase.SystemOut = junit.NewOutput(attachmentsLog(tc.Name, tc.Attachments, o.logs))
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+		durations[pos] += tc.Stop - tc.Start
+	}
+
+	var total int64
+	for pos := range suites.Suites {
+		suite := &suites.Suites[pos]
+		suite.Time = junitTime(durations[pos])
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		total += durations[pos]
+	}
+
+	suites.Time = junitTime(total)
+
+	return suites
+}
+
+// testPackage returns the package label of the test.
+func testPackage(tc allure.Test) string {
+	if pkg, ok := labelValue(tc.Labels, "package"); ok {
+		return pkg
+	}
+
+	return defaultPackageName
+}
+
+// labelValue returns the value of the first label with the given name.
+func labelValue(labels []allure.Label, name string) (string, bool) {
+	for _, label := range labels {
+		if label.Name == name {
+			return label.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// junitTime formats milliseconds as JUnit seconds.
+func junitTime(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
@@ -0,0 +1,183 @@
+package exporter
+
+import (
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/robotomize/go-allure/internal/allure"
+	"github.com/robotomize/go-allure/internal/junit"
+)
+
+func TestWriter_JUnitSuites(t *testing.T) {
+	t.Parallel()
+
+	o := &writer{
+		tests: []allure.Test{
+			{
+				Name:   "TestFilter",
+				Status: allure.StatusFail,
+				Start:  1000,
+				Stop:   1500,
+				Labels: []allure.Label{{Name: "package", Value: "slice"}},
+				StatusDetails: &allure.StatusDetails{
+					Message: "got: [3 4], want: [3]",
+					Trace:   "slice_test.go:96",
+				},
+				Attachments: []allure.Attachment{{Name: "TestFilter", Source: "filter-attachment.txt"}},
+			},
+			{
+				Name:   "TestMap",
+				Status: allure.StatusSkip,
+				Start:  1500,
+				Stop:   1500,
+				Labels: []allure.Label{{Name: "package", Value: "slice"}},
+			},
+			{
+				Name:   "TestPanic",
+				Status: allure.StatusBroken,
+				Start:  2000,
+				Stop:   2250,
+			},
+		},
+		logs: map[string][]byte{"filter-attachment.txt": []byte("=== RUN   TestFilter\n")},
+	}
+
+	expected := junit.TestSuites{
+		Tests:    3,
+		Failures: 1,
+		Errors:   1,
+		Skipped:  1,
+		Time:     "0.750",
+		Suites: []junit.TestSuite{
+			{
+				Name:     "slice",
+				Tests:    2,
+				Failures: 1,
+				Skipped:  1,
+				Time:     "0.500",
+				TestCases: []junit.TestCase{
+					{
+						Name:      "TestFilter",
+						ClassName: "slice",
+						Time:      "0.500",
+						Failure: &junit.Result{
+							Message: "got: [3 4], want: [3]",
+							Type:    "failure",
+							Text:    "slice_test.go:96",
+						},
+						SystemOut: &junit.Output{Text: "=== RUN   TestFilter\n"},
+					},
+					{Name: "TestMap", ClassName: "slice", Time: "0.000", Skipped: &junit.Result{}},
+				},
+			},
+			{
+				Name:   defaultPackageName,
+				Tests:  1,
+				Errors: 1,
+				Time:   "0.250",
+				TestCases: []junit.TestCase{
+					{
+						Name:      "TestPanic",
+						ClassName: defaultPackageName,
+						Time:      "0.250",
+						Error:     &junit.Result{Type: "error"},
+					},
+				},
+			},
+		},
+	}
+
+	got := o.junitSuites()
+	if diff := cmp.Diff(
+		expected, got, cmpopts.IgnoreFields(junit.TestSuite{}, "Timestamp", "Hostname"),
+	); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestWriter_WriteJUnit(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		log      string
+		message  string
+		expected string
+	}{
+		{
+			name:     "test_plain_log",
+			log:      "=== RUN   TestFilter\n    slice_test.go:96: <nil> & done\n",
+			message:  "want <nil>",
+			expected: "=== RUN   TestFilter\n    slice_test.go:96: <nil> & done\n",
+		},
+		{
+			name:     "test_ansi_log",
+			log:      "\x1b[31m--- FAIL: TestFilter\x1b[0m\n",
+			message:  "\x1b[31mfailed\x1b[0m",
+			expected: "�[31m--- FAIL: TestFilter�[0m\n",
+		},
+		{
+			name:     "test_control_bytes",
+			log:      "a\x00b\x07c\td\r\n",
+			expected: "a�b�c\td\r\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				pth := filepath.Join(t.TempDir(), "junit.xml")
+				o := &writer{
+					junitPth: pth,
+					tests: []allure.Test{
+						{
+							Name:          "TestFilter",
+							Status:        allure.StatusFail,
+							Labels:        []allure.Label{{Name: "package", Value: "slice"}},
+							StatusDetails: &allure.StatusDetails{Message: tc.message},
+							Attachments:   []allure.Attachment{{Name: "TestFilter", Source: "log-attachment.txt"}},
+						},
+					},
+					logs: map[string][]byte{"log-attachment.txt": []byte(tc.log)},
+				}
+
+				if err := o.writeJUnit(); err != nil {
+					t.Fatalf("writeJUnit: %v", err)
+				}
+
+				b, err := os.ReadFile(pth)
+				if err != nil {
+					t.Fatalf("os.ReadFile: %v", err)
+				}
+
+				var suites junit.TestSuites
+				if err = xml.Unmarshal(b, &suites); err != nil {
+					t.Fatalf("xml.Unmarshal: %v", err)
+				}
+
+				if diff := cmp.Diff(tc.expected, suites.Suites[0].TestCases[0].SystemOut.String()); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				f, err := os.Open(pth)
+				if err != nil {
+					t.Fatalf("os.Open: %v", err)
+				}
+				defer f.Close()
+
+				if _, err = junit.NewReader(f).ReadAll(context.Background()); err != nil {
+					t.Errorf("junit.Reader.ReadAll: %v", err)
+				}
+			},
+		)
+	}
+}
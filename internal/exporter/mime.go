@@ -0,0 +1,95 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+const (
+	MimeTextPlain = "text/plain"
+	MimeJSON      = "application/json"
+)
+
+var mimeExtensions = map[string]string{
+	MimeTextPlain:              ".txt",
+	MimeJSON:                   ".json",
+	MimeGo:                     ".go",
+	"text/html":                ".html",
+	"text/xml":                 ".xml",
+	"text/csv":                 ".csv",
+	"image/png":                ".png",
+	"image/jpeg":               ".jpg",
+	"image/gif":                ".gif",
+	"image/webp":               ".webp",
+	"image/bmp":                ".bmp",
+	"image/svg+xml":            ".svg",
+	"application/pdf":          ".pdf",
+	"application/zip":          ".zip",
+	"application/x-gzip":       ".gz",
+	"video/mp4":                ".mp4",
+	"video/webm":               ".webm",
+	"application/xml":          ".xml",
+	"application/x-yaml":       ".yaml",
+	"application/octet-stream": ".bin",
+}
+
+// NewAttachment creates an attachment with a unique source named after its MIME type. A given MIME type is kept,
+// so files attached at runtime carry their own type, an empty one is detected from the body.
+func NewAttachment(name, mimeType string, body []byte) Attachment {
+	if mimeType == "" {
+		mimeType = DetectMime(body)
+	}
+
+	return Attachment{
+		Name:   name,
+		Mime:   mimeType,
+		Source: fmt.Sprintf("%s-attachment%s", uuid.New().String(), mimeExtension(mimeType)),
+		Body:   body,
+	}
+}
+
+// DetectMime returns application/json for valid JSON documents, the sniffed type of binary content
+// such as images and archives and text/plain for any other text, so logs starting with markup stay plain text.
+func DetectMime(body []byte) string {
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		if json.Valid(trimmed) {
+			return MimeJSON
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(body))
+	if err != nil || strings.HasPrefix(mediaType, "text/") {
+		return MimeTextPlain
+	}
+
+	return mediaType
+}
+
+func mimeExtension(mimeType string) string {
+	if ext, ok := mimeExtensions[mimeType]; ok {
+		return ext
+	}
+
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+
+	return ""
+}
+
+// allureAttachment returns the attachment reference stored in the allure result.
+func (a Attachment) allureAttachment() allure.Attachment {
+	return allure.Attachment{
+		Name:   a.Name,
+		Source: a.Source,
+		Type:   a.Mime,
+	}
+}
@@ -0,0 +1,162 @@
+package exporter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetectMime(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		body     []byte
+		expected string
+	}{
+		{
+			name:     "test_log",
+			body:     []byte("=== RUN   TestFilter\n--- FAIL: TestFilter (0.00s)\n"),
+			expected: MimeTextPlain,
+		},
+		{
+			name:     "test_empty",
+			body:     []byte{},
+			expected: MimeTextPlain,
+		},
+		{
+			name:     "test_log_starting_with_html",
+			body:     []byte("<html> rendered by the handler\n--- FAIL: TestRender\n"),
+			expected: MimeTextPlain,
+		},
+		{
+			name:     "test_log_starting_with_xml",
+			body:     []byte("<?xml version=\"1.0\"?><testsuite/>\n"),
+			expected: MimeTextPlain,
+		},
+		{
+			name:     "test_log_starting_with_tag",
+			body:     []byte("<nil> is not expected\n"),
+			expected: MimeTextPlain,
+		},
+		{
+			name:     "test_json_object",
+			body:     []byte("  {\"status\": \"ok\"}\n"),
+			expected: MimeJSON,
+		},
+		{
+			name:     "test_json_array",
+			body:     []byte("[1, 2, 3]"),
+			expected: MimeJSON,
+		},
+		{
+			name:     "test_invalid_json",
+			body:     []byte("{\"status\": ok}"),
+			expected: MimeTextPlain,
+		},
+		{
+			name:     "test_png",
+			body:     []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"),
+			expected: "image/png",
+		},
+		{
+			name:     "test_pdf",
+			body:     []byte("%PDF-1.7\n"),
+			expected: "application/pdf",
+		},
+		{
+			name:     "test_gzip",
+			body:     []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00"),
+			expected: "application/x-gzip",
+		},
+		{
+			name:     "test_binary",
+			body:     []byte{0x00, 0x01, 0x02, 0x03},
+			expected: "application/octet-stream",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				if diff := cmp.Diff(tc.expected, DetectMime(tc.body)); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+func TestNewAttachment(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		mime         string
+		body         []byte
+		expectedMime string
+		expectedExt  string
+	}{
+		{
+			name:         "test_detected_text",
+			body:         []byte("log"),
+			expectedMime: MimeTextPlain,
+			expectedExt:  ".txt",
+		},
+		{
+			name:         "test_detected_json",
+			body:         []byte("{}"),
+			expectedMime: MimeJSON,
+			expectedExt:  ".json",
+		},
+		{
+			name:         "test_given_mime",
+			mime:         MimeGo,
+			body:         []byte("func TestFilter(t *testing.T) {}"),
+			expectedMime: MimeGo,
+			expectedExt:  ".go",
+		},
+		{
+			name:         "test_given_mime_over_detected",
+			mime:         "text/csv",
+			body:         []byte("[1,2]"),
+			expectedMime: "text/csv",
+			expectedExt:  ".csv",
+		},
+		{
+			name:         "test_unknown_mime",
+			mime:         "application/x-unknown",
+			body:         []byte("body"),
+			expectedMime: "application/x-unknown",
+			expectedExt:  "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				attachment := NewAttachment("log", tc.mime, tc.body)
+				if diff := cmp.Diff(tc.expectedMime, attachment.Mime); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				source := regexp.MustCompile(`^[0-9a-f-]{36}-attachment` + regexp.QuoteMeta(tc.expectedExt) + `$`)
+				if !source.MatchString(attachment.Source) || !isAllureArtifact(attachment.Source) {
+					t.Errorf("got: %s, want: <uuid>-attachment%s", attachment.Source, tc.expectedExt)
+				}
+
+				reference := attachment.allureAttachment()
+				if reference.Type != tc.expectedMime || reference.Source != attachment.Source || reference.Name != "log" {
+					t.Errorf("got: %+v, want: the attachment reference", reference)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,94 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+const resultFileSuffix = "-result.json"
+
+// ReadReport reads allure results and the attachments they reference from the given directory.
+func ReadReport(ctx context.Context, dir string) (Report, error) {
+	var report Report
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return report, fmt.Errorf("os.ReadDir: %w", err)
+	}
+
+	for _, entry := range entries {
+		if err = ctx.Err(); err != nil {
+			return report, err
+		}
+
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), resultFileSuffix) {
+			continue
+		}
+
+		b, readErr := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if readErr != nil {
+			return report, fmt.Errorf("os.ReadFile: %w", readErr)
+		}
+
+		var tc allure.Test
+		if err = json.Unmarshal(b, &tc); err != nil {
+			return report, fmt.Errorf("json.Unmarshal %s: %w", entry.Name(), err)
+		}
+
+		report.Tests = append(report.Tests, tc)
+	}
+
+	// Load the attachments of the tests and their steps, missing files are skipped.
+	seen := make(map[string]struct{})
+	var readAttachments func(attachments []allure.Attachment, steps []allure.Step) error
+	readAttachments = func(attachments []allure.Attachment, steps []allure.Step) error {
+		for _, attachment := range attachments {
+			if _, ok := seen[attachment.Source]; ok {
+				continue
+			}
+
+			seen[attachment.Source] = struct{}{}
+
+			body, readErr := os.ReadFile(filepath.Join(dir, filepath.Base(attachment.Source)))
+			if readErr != nil {
+				if errors.Is(readErr, os.ErrNotExist) {
+					continue
+				}
+
+				return fmt.Errorf("os.ReadFile: %w", readErr)
+			}
+
+			report.Attachments = append(
+				report.Attachments, Attachment{
+					Name:   attachment.Name,
+					Mime:   attachment.Type,
+					Source: attachment.Source,
+					Body:   body,
+				},
+			)
+		}
+
+		for _, step := range steps {
+			if stepErr := readAttachments(step.Attachments, step.Steps); stepErr != nil {
+				return stepErr
+			}
+		}
+
+		return nil
+	}
+
+	for _, tc := range report.Tests {
+		if err = readAttachments(tc.Attachments, tc.Steps); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
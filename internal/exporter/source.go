@@ -0,0 +1,68 @@
+package exporter
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/robotomize/go-allure/internal/allure"
+	"github.com/robotomize/go-allure/internal/parser"
+)
+
+// MimeGo is the type of the test function source attachment.
+const MimeGo = "text/x-go"
+
+// SourceLinks builds links to the test source in the VCS web UI.
+type SourceLinks struct {
+	// Template is the link URL with {file}, {line} and {sha} placeholders:
+	// https://github.com/robotomize/go-allure/blob/{sha}/{file}#L{line}.
+	Template string
+	// Root is the repository root the {file} path is relative to.
+	Root string
+	// SHA is the commit substituted for {sha}.
+	SHA string
+}
+
+// link returns the link to the test function or false if the file is outside the repository root.
+func (s SourceLinks) link(file parser.GoTestMethod) (allure.Link, bool) {
+	url, ok := s.url(filepath.Join(file.Dir, file.FileName), file.TestFileLine)
+	if !ok {
+		return allure.Link{}, false
+	}
+
+	return allure.Link{Name: "source", URL: url, Type: "link"}, true
+}
+
+// url returns the URL of the line of the file or false if the file is outside the repository root.
+func (s SourceLinks) url(pth string, line int) (string, bool) {
+	rel, err := filepath.Rel(s.Root, pth)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+
+	return strings.NewReplacer(
+		"{file}", filepath.ToSlash(rel),
+		"{line}", strconv.Itoa(line),
+		"{sha}", s.SHA,
+	).Replace(s.Template), true
+}
+
+// testFilePath returns the path of the test file relative to the repository root of the test,
+// the module root is used outside a git work tree.
+func (e *exporter) testFilePath(key string, file parser.GoTestMethod) string {
+	root := file.ModuleDir
+	switch {
+	case e.git[key].repo != nil:
+		root = e.git[key].repo.Root
+	case e.opts.sourceLinks != nil && e.opts.sourceLinks.Root != "":
+		root = e.opts.sourceLinks.Root
+	default:
+	}
+
+	rel, err := filepath.Rel(root, filepath.Join(file.Dir, file.FileName))
+	if root == "" || err != nil || strings.HasPrefix(rel, "..") {
+		return file.FileName
+	}
+
+	return filepath.ToSlash(rel)
+}
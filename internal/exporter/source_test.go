@@ -0,0 +1,102 @@
+package exporter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/allure"
+	"github.com/robotomize/go-allure/internal/parser"
+)
+
+func TestSourceLinks_Link(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(string(filepath.Separator), "src", "go-allure")
+	links := SourceLinks{
+		Template: "https://github.com/robotomize/go-allure/blob/{sha}/{file}#L{line}",
+		Root:     root,
+		SHA:      "ba58218",
+	}
+
+	testCases := []struct {
+		name     string
+		links    SourceLinks
+		file     parser.GoTestMethod
+		expected allure.Link
+		ok       bool
+	}{
+		{
+			name:  "test_nested_package",
+			links: links,
+			file: parser.GoTestMethod{
+				FileName: "slice_test.go", TestFileLine: 12, Dir: filepath.Join(root, "internal", "slice"),
+			},
+			expected: allure.Link{
+				Name: "source",
+				URL:  "https://github.com/robotomize/go-allure/blob/ba58218/internal/slice/slice_test.go#L12",
+				Type: "link",
+			},
+			ok: true,
+		},
+		{
+			name:  "test_root_package",
+			links: links,
+			file:  parser.GoTestMethod{FileName: "main_test.go", TestFileLine: 3, Dir: root},
+			expected: allure.Link{
+				Name: "source",
+				URL:  "https://github.com/robotomize/go-allure/blob/ba58218/main_test.go#L3",
+				Type: "link",
+			},
+			ok: true,
+		},
+		{
+			name: "test_placeholders_repeated",
+			links: SourceLinks{
+				Template: "https://git.example.com/{file}?ref={sha}&line={line}#{file}",
+				Root:     root,
+				SHA:      "main",
+			},
+			file: parser.GoTestMethod{FileName: "a_test.go", TestFileLine: 7, Dir: filepath.Join(root, "a")},
+			expected: allure.Link{
+				Name: "source",
+				URL:  "https://git.example.com/a/a_test.go?ref=main&line=7#a/a_test.go",
+				Type: "link",
+			},
+			ok: true,
+		},
+		{
+			name:  "test_outside_root",
+			links: links,
+			file: parser.GoTestMethod{
+				FileName: "a_test.go", TestFileLine: 7, Dir: filepath.Join(string(filepath.Separator), "src", "other"),
+			},
+		},
+		{
+			name:  "test_sibling_with_root_prefix",
+			links: links,
+			file: parser.GoTestMethod{
+				FileName: "a_test.go", TestFileLine: 7, Dir: root + "-fork",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				got, ok := tc.links.link(tc.file)
+				if ok != tc.ok {
+					t.Fatalf("got: %v, want: %v", ok, tc.ok)
+				}
+
+				if diff := cmp.Diff(tc.expected, got); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,179 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+const (
+	summarySlowestTests = 10
+	summaryLogLines     = 30
+)
+
+type summaryCounts struct {
+	passed, failed, broken, skipped int
+	duration                        int64
+}
+
+func (c *summaryCounts) add(tc allure.Test) {
+	switch tc.Status {
+	case allure.StatusPass:
+		c.passed++
+	case allure.StatusFail:
+		c.failed++
+	case allure.StatusBroken:
+		c.broken++
+	case allure.StatusSkip:
+		c.skipped++
+	default:
+	}
+
+	c.duration += tc.Stop - tc.Start
+}
+
+// WriteSummary renders a Markdown summary of the report suitable for CI job summaries and PR comments.
+func WriteSummary(w io.Writer, report Report) error {
+	buf := bytes.NewBuffer(make([]byte, 0))
+
+	var total summaryCounts
+	var start, stop int64
+
+	packages := make([]string, 0)
+	counts := make(map[string]*summaryCounts)
+	for _, tc := range report.Tests {
+		total.add(tc)
+
+		pkg := testPackage(tc)
+		c, ok := counts[pkg]
+		if !ok {
+			c = &summaryCounts{}
+			counts[pkg] = c
+			packages = append(packages, pkg)
+		}
+
+		c.add(tc)
+
+		if start == 0 || tc.Start < start {
+			start = tc.Start
+		}
+
+		if tc.Stop > stop {
+			stop = tc.Stop
+		}
+	}
+
+	sort.Strings(packages)
+
+	buf.WriteString("## Test results\n\n")
+	_, _ = fmt.Fprintf(
+		buf, "**%d** tests: **%d** passed, **%d** failed, **%d** broken, **%d** skipped in %s\n\n",
+		len(report.Tests), total.passed, total.failed, total.broken, total.skipped, summaryDuration(stop-start),
+	)
+
+	// Write the per package counts.
+	buf.WriteString("| Package | Passed | Failed | Broken | Skipped | Duration |\n")
+	buf.WriteString("|---|---:|---:|---:|---:|---:|\n")
+	for _, pkg := range packages {
+		c := counts[pkg]
+		_, _ = fmt.Fprintf(
+			buf, "| `%s` | %d | %d | %d | %d | %s |\n",
+			summaryCell(pkg), c.passed, c.failed, c.broken, c.skipped, summaryDuration(c.duration),
+		)
+	}
+
+	// Write the slowest tests.
+	slowest := make([]allure.Test, len(report.Tests))
+	copy(slowest, report.Tests)
+	sort.SliceStable(
+		slowest, func(i, j int) bool {
+			return slowest[i].Stop-slowest[i].Start > slowest[j].Stop-slowest[j].Start
+		},
+	)
+
+	if len(slowest) > summarySlowestTests {
+		slowest = slowest[:summarySlowestTests]
+	}
+
+	if len(slowest) > 0 {
+		buf.WriteString("\n### Slowest tests\n\n")
+		buf.WriteString("| Test | Package | Duration |\n")
+		buf.WriteString("|---|---|---:|\n")
+		for _, tc := range slowest {
+			_, _ = fmt.Fprintf(
+				buf, "| %s | `%s` | %s |\n",
+				summaryCell(summaryLine(tc.Name)), summaryCell(testPackage(tc)), summaryDuration(tc.Stop-tc.Start),
+			)
+		}
+	}
+
+	// Write collapsible failure details with the tail of the test log.
+	logs := make(map[string][]byte, len(report.Attachments))
+	for _, attachment := range report.Attachments {
+		logs[attachment.Source] = attachment.Body
+	}
+
+	var failuresHeader bool
+	for _, tc := range report.Tests {
+		if tc.Status != allure.StatusFail && tc.Status != allure.StatusBroken {
+			continue
+		}
+
+		if !failuresHeader {
+			buf.WriteString("\n### Failures\n\n")
+			failuresHeader = true
+		}
+
+		_, _ = fmt.Fprintf(
+			buf, "<details>\n<summary>%s <code>%s</code> %s</summary>\n\n",
+			summaryLine(tc.Status), summaryLine(testPackage(tc)), summaryLine(tc.Name),
+		)
+
+		if details := tc.StatusDetails; details != nil && details.Message != "" {
+			_, _ = fmt.Fprintf(buf, "%s\n\n", html.EscapeString(details.Message))
+		}
+
+		if log := attachmentsLog(tc.Name, tc.Attachments, logs); log != "" {
+			_, _ = fmt.Fprintf(buf, "```\n%s\n```\n\n", logExcerpt([]byte(log), summaryLogLines))
+		}
+
+		buf.WriteString("</details>\n\n")
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("write summary: %w", err)
+	}
+
+	return nil
+}
+
+// logExcerpt returns the last lines of the log.
+func logExcerpt(log []byte, lines int) string {
+	rows := strings.Split(strings.TrimRight(string(log), "\n"), "\n")
+	if len(rows) > lines {
+		rows = append([]string{"..."}, rows[len(rows)-lines:]...)
+	}
+
+	// Keep the code block intact if the log contains fences.
+	return strings.ReplaceAll(strings.Join(rows, "\n"), "```", "'''")
+}
+
+// summaryCell escapes the pipes and line breaks that would split a Markdown table cell.
+func summaryCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// summaryLine escapes the text of a single line HTML element.
+func summaryLine(s string) string {
+	return html.EscapeString(strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s))
+}
+
+func summaryDuration(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
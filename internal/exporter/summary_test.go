@@ -0,0 +1,127 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+func TestWriteSummary(t *testing.T) {
+	t.Parallel()
+
+	report := Report{
+		Tests: []allure.Test{
+			{
+				Name:   "TestFilter/a|b",
+				Status: allure.StatusFail,
+				Start:  1000,
+				Stop:   1500,
+				Labels: []allure.Label{{Name: "package", Value: "slice"}},
+				StatusDetails: &allure.StatusDetails{
+					Message: "want <nil>\n</details>",
+				},
+				Attachments: []allure.Attachment{{Name: "TestFilter/a|b", Source: "filter-attachment.txt"}},
+			},
+			{
+				Name:   "TestMap/<b>bold</b>\nnext",
+				Status: allure.StatusPass,
+				Start:  1500,
+				Stop:   1600,
+				Labels: []allure.Label{{Name: "package", Value: "slice"}},
+			},
+		},
+		Attachments: []Attachment{
+			{Source: "filter-attachment.txt", Body: []byte("=== RUN   TestFilter\n```\n--- FAIL: TestFilter\n")},
+		},
+	}
+
+	expected := "## Test results\n\n" +
+		"**2** tests: **1** passed, **1** failed, **0** broken, **0** skipped in 600ms\n\n" +
+		"| Package | Passed | Failed | Broken | Skipped | Duration |\n" +
+		"|---|---:|---:|---:|---:|---:|\n" +
+		"| `slice` | 1 | 1 | 0 | 0 | 600ms |\n" +
+		"\n### Slowest tests\n\n" +
+		"| Test | Package | Duration |\n" +
+		"|---|---|---:|\n" +
+		"| TestFilter/a\\|b | `slice` | 500ms |\n" +
+		"| TestMap/&lt;b&gt;bold&lt;/b&gt; next | `slice` | 100ms |\n" +
+		"\n### Failures\n\n" +
+		"<details>\n<summary>failed <code>slice</code> TestFilter/a|b</summary>\n\n" +
+		"want &lt;nil&gt;\n&lt;/details&gt;\n\n" +
+		"```\n=== RUN   TestFilter\n'''\n--- FAIL: TestFilter\n```\n\n" +
+		"</details>\n\n"
+
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := WriteSummary(buf, report); err != nil {
+		t.Fatalf("WriteSummary: %v", err)
+	}
+
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSummaryEscape(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		escape   func(string) string
+		input    string
+		expected string
+	}{
+		{
+			name:     "test_cell_pipe",
+			escape:   summaryCell,
+			input:    "a|b",
+			expected: `a\|b`,
+		},
+		{
+			name:     "test_cell_newlines",
+			escape:   summaryCell,
+			input:    "a\nb\r\nc\rd",
+			expected: "a b c d",
+		},
+		{
+			name:     "test_line_html",
+			escape:   summaryLine,
+			input:    `<script>alert("x")</script> & more`,
+			expected: "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; more",
+		},
+		{
+			name:     "test_line_newlines",
+			escape:   summaryLine,
+			input:    "a\nb",
+			expected: "a b",
+		},
+		{
+			name:     "test_log_excerpt_fences",
+			escape:   func(s string) string { return logExcerpt([]byte(s), summaryLogLines) },
+			input:    "```go\nx\n```\n",
+			expected: "'''go\nx\n'''",
+		},
+		{
+			name:     "test_log_excerpt_tail",
+			escape:   func(s string) string { return logExcerpt([]byte(s), 2) },
+			input:    strings.Join([]string{"1", "2", "3", "4"}, "\n"),
+			expected: "...\n3\n4",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				if diff := cmp.Diff(tc.expected, tc.escape(tc.input)); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,204 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+const (
+	defaultUploadBatchSize = 100
+	uploadRetryDelay       = 500 * time.Millisecond
+)
+
+// Upload configures sending results to an allure-docker-service compatible /send-results endpoint.
+// Allure TestOps is not supported, its launches are uploaded with allurectl.
+type Upload struct {
+	// Endpoint is the full send-results URL, e.g. http://localhost:5050/allure-docker-service/send-results.
+	Endpoint string
+	Token    string
+	Project  string
+
+	// BatchSize is the number of files sent in a single request.
+	BatchSize int
+	// Retries is the number of times a failed request is retried, 0 disables retries.
+	Retries int
+	// DryRun prints the requests to Log instead of sending them.
+	DryRun bool
+	Log    io.Writer
+
+	Client *http.Client
+}
+
+type uploadFile struct {
+	FileName      string `json:"file_name"`
+	ContentBase64 string `json:"content_base64"`
+}
+
+type uploadRequest struct {
+	Results []uploadFile `json:"results"`
+}
+
+// UploadTo sends the written tests and attachments to the allure server on Flush.
+func UploadTo(upload Upload) WriterOption {
+	return func(w *writer) {
+		if upload.BatchSize <= 0 {
+			upload.BatchSize = defaultUploadBatchSize
+		}
+
+		if upload.Retries < 0 {
+			upload.Retries = 0
+		}
+
+		if upload.Log == nil {
+			upload.Log = io.Discard
+		}
+
+		if upload.Client == nil {
+			upload.Client = http.DefaultClient
+		}
+
+		w.upload = &upload
+	}
+}
+
+// uploadResults sends result and attachment files in batches.
+func (o *writer) uploadResults(ctx context.Context) error {
+	files := make([]uploadFile, 0, len(o.tests)+len(o.logs))
+	for _, tc := range o.tests {
+		b, err := json.Marshal(tc)
+		if err != nil {
+			return fmt.Errorf("json.Marshal: %w", err)
+		}
+
+		files = append(
+			files, uploadFile{
+				FileName:      fmt.Sprintf("%s%s", tc.UUID, resultFileSuffix),
+				ContentBase64: base64.StdEncoding.EncodeToString(b),
+			},
+		)
+	}
+
+	sources := make([]string, 0, len(o.logs))
+	for source := range o.logs {
+		sources = append(sources, source)
+	}
+
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		files = append(
+			files, uploadFile{
+				FileName:      source,
+				ContentBase64: base64.StdEncoding.EncodeToString(o.logs[source]),
+			},
+		)
+	}
+
+	if o.environment != nil {
+		files = append(
+			files, uploadFile{
+				FileName:      environmentFile,
+				ContentBase64: base64.StdEncoding.EncodeToString(o.environment),
+			},
+		)
+	}
+
+	for start := 0; start < len(files); start += o.upload.BatchSize {
+		end := start + o.upload.BatchSize
+		if end > len(files) {
+			end = len(files)
+		}
+
+		if err := o.sendBatch(ctx, files[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendBatch posts a batch of files, retrying network errors and 429/5xx responses with a backoff.
+func (o *writer) sendBatch(ctx context.Context, files []uploadFile) error {
+	endpoint, err := url.Parse(o.upload.Endpoint)
+	if err != nil {
+		return fmt.Errorf("url.Parse: %w", err)
+	}
+
+	if o.upload.Project != "" {
+		query := endpoint.Query()
+		query.Set("project_id", o.upload.Project)
+		endpoint.RawQuery = query.Encode()
+	}
+
+	if o.upload.DryRun {
+		_, _ = fmt.Fprintf(o.upload.Log, "Dry run: POST %s with %d files\n", endpoint.String(), len(files))
+		for _, file := range files {
+			_, _ = fmt.Fprintf(o.upload.Log, "    %s\n", file.FileName)
+		}
+
+		return nil
+	}
+
+	body, err := json.Marshal(uploadRequest{Results: files})
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	delay := uploadRetryDelay
+	for attempt := 1; ; attempt++ {
+		retry, sendErr := o.send(ctx, endpoint.String(), body)
+		if sendErr == nil {
+			return nil
+		}
+
+		if !retry || attempt > o.upload.Retries {
+			return sendErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+// send makes a single request and reports whether a failed one may be retried.
+func (o *writer) send(ctx context.Context, endpoint string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if o.upload.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+o.upload.Token)
+	}
+
+	resp, err := o.upload.Client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("http.Client Do: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+
+	return retry, fmt.Errorf("upload results: unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+}
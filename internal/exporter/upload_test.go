@@ -0,0 +1,168 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+func TestWriter_Upload(t *testing.T) {
+	t.Parallel()
+
+	tests := []allure.Test{{UUID: "1", Name: "TestA"}, {UUID: "2", Name: "TestB"}}
+	attachments := []Attachment{{Name: "TestB", Source: "b-attachment.txt", Body: []byte("log")}}
+
+	testCases := []struct {
+		name          string
+		failures      int
+		status        int
+		retries       int
+		dryRun        bool
+		expectedFiles [][]string
+		expectedErr   bool
+	}{
+		{
+			name:          "test_batches",
+			expectedFiles: [][]string{{"1-result.json", "2-result.json"}, {"b-attachment.txt"}},
+		},
+		{
+			name:          "test_retry",
+			failures:      1,
+			status:        http.StatusServiceUnavailable,
+			retries:       1,
+			expectedFiles: [][]string{{"1-result.json", "2-result.json"}, {"b-attachment.txt"}},
+		},
+		{
+			name:        "test_retry_exhausted",
+			failures:    3,
+			status:      http.StatusServiceUnavailable,
+			retries:     2,
+			expectedErr: true,
+		},
+		{
+			name:        "test_no_retries",
+			failures:    1,
+			status:      http.StatusServiceUnavailable,
+			expectedErr: true,
+		},
+		{
+			name:        "test_no_retry_bad_request",
+			failures:    1,
+			status:      http.StatusBadRequest,
+			retries:     3,
+			expectedErr: true,
+		},
+		{
+			name:   "test_dry_run",
+			dryRun: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				var mu sync.Mutex
+				var requests int
+				files := make([][]string, 0)
+
+				srv := httptest.NewServer(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							mu.Lock()
+							defer mu.Unlock()
+
+							requests++
+							if requests <= tc.failures {
+								w.WriteHeader(tc.status)
+								return
+							}
+
+							if r.Header.Get("Authorization") != "Bearer token" || r.URL.Query().Get("project_id") != "go" {
+								w.WriteHeader(http.StatusUnauthorized)
+								return
+							}
+
+							var req uploadRequest
+							if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+								w.WriteHeader(http.StatusBadRequest)
+								return
+							}
+
+							names := make([]string, 0, len(req.Results))
+							for _, file := range req.Results {
+								names = append(names, file.FileName)
+							}
+
+							files = append(files, names)
+						},
+					),
+				)
+				defer srv.Close()
+
+				log := bytes.NewBuffer(make([]byte, 0))
+				w := NewWriter(
+					UploadTo(
+						Upload{
+							Endpoint:  srv.URL + "/send-results",
+							Token:     "token",
+							Project:   "go",
+							BatchSize: 2,
+							Retries:   tc.retries,
+							DryRun:    tc.dryRun,
+							Log:       log,
+						},
+					),
+				)
+
+				ctx := context.Background()
+				if err := w.WriteReport(ctx, tests); err != nil {
+					t.Fatalf("WriteReport: %v", err)
+				}
+
+				if err := w.WriteAttachments(ctx, attachments); err != nil {
+					t.Fatalf("WriteAttachments: %v", err)
+				}
+
+				err := w.Flush(ctx)
+				if (err != nil) != tc.expectedErr {
+					t.Fatalf("got: %v, want error: %v", err, tc.expectedErr)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if tc.expectedErr {
+					if requests != tc.failures {
+						t.Errorf("got: %d requests, want: %d", requests, tc.failures)
+					}
+
+					return
+				}
+
+				if tc.dryRun {
+					if requests != 0 || !strings.Contains(log.String(), "b-attachment.txt") {
+						t.Errorf("got: %d requests, log %q, want: no requests", requests, log.String())
+					}
+
+					return
+				}
+
+				if diff := cmp.Diff(tc.expectedFiles, files); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
@@ -1,19 +1,29 @@
 package exporter
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
 
 	"github.com/robotomize/go-allure/internal/allure"
 )
 
+const environmentFile = "environment.properties"
+
 type Writer interface {
 	WriteReport(ctx context.Context, tests []allure.Test) error
 	WriteAttachments(ctx context.Context, attachments []Attachment) error
+	WriteEnvironment(ctx context.Context, env map[string]string) error
+	Flush(ctx context.Context) error
 }
 
 type WriterOption func(*writer)
@@ -24,14 +34,78 @@ func WriteToFile(pth string) WriterOption {
 	}
 }
 
+// WriteToRunSubdir writes the results into the run subdirectory of the output path,
+// WithClean then cleans the output path including the run subdirectories of previous runs.
+func WriteToRunSubdir(pth, run string) WriterOption {
+	return func(w *writer) {
+		w.pth = filepath.Join(pth, run)
+		w.cleanPth = pth
+	}
+}
+
 func WriteReportTo(writers ...io.Writer) WriterOption {
 	return func(w *writer) {
 		w.reportWriters = append(w.reportWriters, writers...)
 	}
 }
 
+// WriteJUnitTo writes a JUnit XML report of the written tests to the given path on Flush.
+func WriteJUnitTo(pth string) WriterOption {
+	return func(w *writer) {
+		w.junitPth = pth
+	}
+}
+
+// WriteCTRFTo writes a CTRF JSON report of the written tests to the given path on Flush.
+func WriteCTRFTo(pth string) WriterOption {
+	return func(w *writer) {
+		w.ctrfPth = pth
+	}
+}
+
+// WriteHTMLTo writes a self-contained HTML report of the written tests to the given path on Flush.
+func WriteHTMLTo(pth string) WriterOption {
+	return func(w *writer) {
+		w.htmlPth = pth
+	}
+}
+
+// WriteArchiveTo streams results and attachments into a tar.gz or zip archive closed on Flush.
+func WriteArchiveTo(pth string) WriterOption {
+	return func(w *writer) {
+		w.archivePth = pth
+	}
+}
+
+// WithFsync syncs every written file to disk before it is renamed into place.
+func WithFsync() WriterOption {
+	return func(w *writer) {
+		w.fsync = true
+	}
+}
+
+// WithWorkers limits the number of files written concurrently.
+func WithWorkers(n int) WriterOption {
+	return func(w *writer) {
+		if n > 0 {
+			w.workers = n
+		}
+	}
+}
+
+// WithClean removes the allure artifacts and run subdirectories of previous runs from the output directory before writing.
+func WithClean() WriterOption {
+	return func(w *writer) {
+		w.clean = true
+	}
+}
+
 func NewWriter(opts ...WriterOption) Writer {
-	w := writer{reportWriters: []io.Writer{io.Discard}}
+	w := writer{
+		reportWriters: []io.Writer{io.Discard},
+		logs:          make(map[string][]byte),
+		workers:       runtime.NumCPU(),
+	}
 	for _, o := range opts {
 		o(&w)
 	}
@@ -41,11 +115,36 @@ func NewWriter(opts ...WriterOption) Writer {
 
 type writer struct {
 	pth           string
+	junitPth      string
+	ctrfPth       string
+	htmlPth       string
+	upload        *Upload
+	archivePth    string
+	fsync         bool
+	clean         bool
+	cleanPth      string
+	prepared      bool
+	workers       int
 	reportWriters []io.Writer
+
+	// archive is opened on the first write and closed on Flush.
+	archive archive
+
+	// tests and logs are collected for the aggregated reports written on Flush.
+	tests       []allure.Test
+	logs        map[string][]byte
+	environment []byte
 }
 
 // WriteReport - writeReport allure report to the given path.
-func (o *writer) WriteReport(ctx context.Context, tests []allure.Test) error {
+func (o *writer) WriteReport(ctx context.Context, tests []allure.Test) (err error) {
+	// Remove the unfinished archive if the write fails.
+	defer func() {
+		if err != nil {
+			o.abortArchive()
+		}
+	}()
+
 	// Check if the context is done to return early.
 	if err := ctx.Err(); err != nil {
 		return err
@@ -53,100 +152,361 @@ func (o *writer) WriteReport(ctx context.Context, tests []allure.Test) error {
 
 	// Create the necessary directories in the file system if they don't exist.
 	if len(o.pth) > 0 {
-		if err := mkdir(o.pth); err != nil {
+		if err := o.prepare(); err != nil {
 			return err
 		}
 	}
 
+	if err := o.openArchive(); err != nil {
+		return err
+	}
+
+	if o.aggregate() {
+		o.tests = append(o.tests, tests...)
+	}
+
+	// Use errgroup to limit the number of files written concurrently.
+	wg, grpCtx := errgroup.WithContext(ctx)
+	wg.SetLimit(o.workers)
+
 	// Loop through the Test objects and writeReport each one to a separate text file.
+	var writeErr error
+OuterLoop:
 	for _, tc := range tests {
-		if err := o.writeReport(tc); err != nil {
-			return fmt.Errorf("writeReport test: %w", err)
+		tc := tc
+
+		// Check if the group context is done to break out of the loop.
+		select {
+		case <-grpCtx.Done():
+			break OuterLoop
+		default:
+		}
+
+		// The console output and the archive are written sequentially to keep the order of the tests.
+		if writeErr = o.writeReport(tc); writeErr != nil {
+			writeErr = fmt.Errorf("writeReport test: %w", writeErr)
+			break
+		}
+
+		if o.pth == "" {
+			continue
 		}
+
+		wg.Go(
+			func() error {
+				if err := grpCtx.Err(); err != nil {
+					return err
+				}
+
+				if err := o.writeReportFile(tc); err != nil {
+					return fmt.Errorf("writeReportFile: %w", err)
+				}
+
+				return nil
+			},
+		)
 	}
 
-	return nil
+	// Wait for the started files before returning so no goroutine writes after an error.
+	if err := errors.Join(writeErr, wg.Wait()); err != nil {
+		return err
+	}
+
+	return ctx.Err()
 }
 
 // WriteAttachments writes the attachments to the given path.
-func (o *writer) WriteAttachments(ctx context.Context, attachments []Attachment) error {
+func (o *writer) WriteAttachments(ctx context.Context, attachments []Attachment) (err error) {
+	// Remove the unfinished archive if the write fails.
+	defer func() {
+		if err != nil {
+			o.abortArchive()
+		}
+	}()
+
 	// Return an error if the context is canceled.
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 
+	if o.aggregate() {
+		for _, attachment := range attachments {
+			o.logs[attachment.Source] = attachment.Body
+		}
+	}
+
+	if err := o.openArchive(); err != nil {
+		return err
+	}
+
+	if o.archive != nil {
+		for _, attachment := range attachments {
+			if err := o.archive.add(attachment.Source, attachment.Body); err != nil {
+				return fmt.Errorf("archive add: %w", err)
+			}
+		}
+	}
+
 	if o.pth == "" {
 		return nil
 	}
 
 	// Create the directory if it does not exist.
-	if err := mkdir(o.pth); err != nil {
-		return fmt.Errorf("mkdir: %w", err)
+	if err := o.prepare(); err != nil {
+		return err
 	}
 
+	wg, grpCtx := errgroup.WithContext(ctx)
+	wg.SetLimit(o.workers)
+
 	// Write each attachment file to disk.
+OuterLoop:
 	for _, attachment := range attachments {
-		if err := o.writeAttachmentFile(attachment); err != nil {
-			return err
+		attachment := attachment
+
+		select {
+		case <-grpCtx.Done():
+			break OuterLoop
+		default:
+		}
+
+		wg.Go(
+			func() error {
+				if err := grpCtx.Err(); err != nil {
+					return err
+				}
+
+				return o.writeAttachmentFile(attachment)
+			},
+		)
+	}
+
+	if err := wg.Wait(); err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+// WriteEnvironment writes the environment.properties file shown on the report overview.
+func (o *writer) WriteEnvironment(ctx context.Context, env map[string]string) (err error) {
+	// Remove the unfinished archive if the write fails.
+	defer func() {
+		if err != nil {
+			o.abortArchive()
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	body := environmentProperties(env)
+	if o.aggregate() {
+		o.environment = body
+	}
+
+	if err := o.openArchive(); err != nil {
+		return err
+	}
+
+	if o.archive != nil {
+		if err := o.archive.add(environmentFile, body); err != nil {
+			return fmt.Errorf("archive add: %w", err)
 		}
 	}
 
+	if o.pth == "" {
+		return nil
+	}
+
+	if err := o.prepare(); err != nil {
+		return err
+	}
+
+	if err := writeFile(filepath.Join(o.pth, environmentFile), body, o.fsync); err != nil {
+		return fmt.Errorf("writeFile: %w", err)
+	}
+
 	return nil
 }
 
-// writeAttachmentFile writes the attachment file to the specified path.
-func (o *writer) writeAttachmentFile(attachment Attachment) error {
-	// Get the file path.
-	pth := filepath.Join(o.pth, attachment.Source)
+// Flush writes the aggregated reports built from all the tests and attachments written before.
+func (o *writer) Flush(ctx context.Context) (err error) {
+	// Remove the unfinished archive if the write fails.
+	defer func() {
+		if err != nil {
+			o.abortArchive()
+		}
+	}()
 
-	// Open the file for writing.
-	file, err := os.OpenFile(pth, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
-	if err != nil {
-		return fmt.Errorf("os.OpenFile: %w", err)
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	// Close the file after the function is done executing.
-	defer file.Close()
+	if o.junitPth != "" {
+		if err := o.writeJUnit(); err != nil {
+			return fmt.Errorf("writeJUnit: %w", err)
+		}
+	}
+
+	if o.ctrfPth != "" {
+		if err := o.writeCTRF(); err != nil {
+			return fmt.Errorf("writeCTRF: %w", err)
+		}
+	}
 
-	// Write the attachment body to the file.
-	if _, err = file.Write(attachment.Body); err != nil {
-		return fmt.Errorf("os.OpenFile Write: %w", err)
+	if o.htmlPth != "" {
+		if err := o.writeHTML(); err != nil {
+			return fmt.Errorf("writeHTML: %w", err)
+		}
 	}
 
-	// Sync the file to disk to ensure the data is actually written.
-	if err = file.Sync(); err != nil {
-		return fmt.Errorf("os.OpenFile Sync: %w", err)
+	if o.upload != nil {
+		if err := o.uploadResults(ctx); err != nil {
+			return fmt.Errorf("uploadResults: %w", err)
+		}
+	}
+
+	if o.archive != nil {
+		a := o.archive
+		o.archive = nil
+
+		if err := a.Close(); err != nil {
+			return fmt.Errorf("archive Close: %w", err)
+		}
 	}
 
 	return nil
 }
 
-// writeReport writes the test result to the specified path, if provided.
-func (o *writer) writeReport(tc allure.Test) (err error) {
-	if o.pth != "" {
-		// Open file for writeReport and 0644 permissions
-		pth := filepath.Join(o.pth, fmt.Sprintf("%s-result.json", tc.UUID))
-		file, openErr := os.OpenFile(pth, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
-		if openErr != nil {
-			return fmt.Errorf("os.OpenFile: %w", openErr)
+// prepare cleans the output directory once if requested and creates it if it does not exist.
+func (o *writer) prepare() error {
+	if o.prepared {
+		return nil
+	}
+
+	// A run subdirectory belongs to a single run, another run of the same time must not write into it
+	// or clean it.
+	if o.cleanPth != "" && exists(o.pth) {
+		return fmt.Errorf("run subdirectory %s already exists", o.pth)
+	}
+
+	if o.clean {
+		pth := o.pth
+		if o.cleanPth != "" {
+			pth = o.cleanPth
 		}
 
-		defer func() {
-			if syncErr := file.Sync(); syncErr != nil {
-				err = fmt.Errorf("file Sync: %w", syncErr)
-			}
+		if err := clean(pth); err != nil {
+			return fmt.Errorf("clean: %w", err)
+		}
+	}
+
+	mkdirFn := mkdir
+	if o.cleanPth != "" {
+		mkdirFn = mkdirRun
+	}
 
-			_ = file.Close()
-		}()
+	if err := mkdirFn(o.pth); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+
+	o.prepared = true
+
+	return nil
+}
+
+// openArchive opens the output archive if it is configured and not opened yet.
+func (o *writer) openArchive() error {
+	if o.archivePth == "" || o.archive != nil {
+		return nil
+	}
+
+	a, err := openArchive(o.archivePth)
+	if err != nil {
+		return fmt.Errorf("openArchive: %w", err)
+	}
+
+	o.archive = a
+
+	return nil
+}
+
+// abortArchive removes the archive opened by the failed write.
+func (o *writer) abortArchive() {
+	if o.archive == nil {
+		return
+	}
+
+	_ = o.archive.abort()
+	o.archive = nil
+}
+
+// aggregate reports whether tests and attachments have to be kept until Flush.
+func (o *writer) aggregate() bool {
+	return o.junitPth != "" || o.ctrfPth != "" || o.htmlPth != "" || o.upload != nil
+}
+
+// environmentProperties encodes the environment in the Java properties format sorted by key.
+func environmentProperties(env map[string]string) []byte {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	keyReplacer := strings.NewReplacer(`\`, `\\`, "=", `\=`, ":", `\:`, " ", `\ `, "\n", `\n`)
+	valueReplacer := strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+	buf := bytes.NewBuffer(make([]byte, 0))
+	for _, key := range keys {
+		buf.WriteString(keyReplacer.Replace(key) + "=" + valueReplacer.Replace(env[key]) + "\n")
+	}
+
+	return buf.Bytes()
+}
+
+// writeAttachmentFile writes the attachment file to the specified path.
+func (o *writer) writeAttachmentFile(attachment Attachment) error {
+	if err := writeFile(filepath.Join(o.pth, attachment.Source), attachment.Body, o.fsync); err != nil {
+		return fmt.Errorf("writeFile: %w", err)
+	}
+
+	return nil
+}
+
+// writeReportFile writes the test result file to the specified path.
+func (o *writer) writeReportFile(tc allure.Test) error {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.NewEncoder(buf).Encode(tc); err != nil {
+		return fmt.Errorf("json.NewEncoder.Encode: %w", err)
+	}
+
+	if err := writeFile(filepath.Join(o.pth, fmt.Sprintf("%s-result.json", tc.UUID)), buf.Bytes(), o.fsync); err != nil {
+		return fmt.Errorf("writeFile: %w", err)
+	}
+
+	return nil
+}
+
+// writeReport writes the test result to the console output and the archive, if provided.
+func (o *writer) writeReport(tc allure.Test) error {
+	if o.archive != nil {
+		b, marshalErr := json.Marshal(tc)
+		if marshalErr != nil {
+			return fmt.Errorf("json.Marshal: %w", marshalErr)
+		}
 
-		if encErr := json.NewEncoder(file).Encode(tc); encErr != nil {
-			return fmt.Errorf("json.NewEncoder.Encode: %w", encErr)
+		if addErr := o.archive.add(fmt.Sprintf("%s-result.json", tc.UUID), b); addErr != nil {
+			return fmt.Errorf("archive add: %w", addErr)
 		}
 	}
 
 	w := io.MultiWriter(o.reportWriters...)
 
-	// Encode the test result in JSON format and writeReport it to the console and file output.
+	// Encode the test result in JSON format and writeReport it to the console output.
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "    ")
 	if encErr := encoder.Encode(tc); encErr != nil {
@@ -0,0 +1,280 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+// failingWriter fails the write after the given number of successful ones.
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.writes == 0 {
+		return 0, errors.New("write failed")
+	}
+
+	w.writes--
+
+	return len(p), nil
+}
+
+func TestWriter_WriteReport(t *testing.T) {
+	t.Parallel()
+
+	tests := make([]allure.Test, 0, 50)
+	for i := 0; i < 50; i++ {
+		tests = append(tests, allure.Test{UUID: fmt.Sprintf("%02d", i), Name: fmt.Sprintf("Test%02d", i)})
+	}
+
+	testCases := []struct {
+		name    string
+		workers int
+	}{
+		{
+			name:    "test_single_worker",
+			workers: 1,
+		},
+		{
+			name:    "test_worker_pool",
+			workers: 4,
+		},
+		{
+			name:    "test_workers_exceed_tests",
+			workers: 100,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				dir := filepath.Join(t.TempDir(), "allure-results")
+				console := bytes.NewBuffer(make([]byte, 0))
+				w := NewWriter(WriteToFile(dir), WriteReportTo(console), WithWorkers(tc.workers), WithFsync())
+
+				if err := w.WriteReport(context.Background(), tests); err != nil {
+					t.Fatalf("WriteReport: %v", err)
+				}
+
+				expected := make([]string, 0, len(tests))
+				for _, test := range tests {
+					expected = append(expected, test.UUID+resultFileSuffix)
+
+					b, err := os.ReadFile(filepath.Join(dir, test.UUID+resultFileSuffix))
+					if err != nil {
+						t.Fatalf("os.ReadFile: %v", err)
+					}
+
+					var got allure.Test
+					if err = json.Unmarshal(b, &got); err != nil {
+						t.Fatalf("json.Unmarshal: %v", err)
+					}
+
+					if diff := cmp.Diff(test, got); diff != "" {
+						t.Errorf("mismatch (-want, +got):\n%s", diff)
+					}
+				}
+
+				// No temporary files are left behind.
+				if diff := cmp.Diff(expected, dirEntries(t, dir)); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				// The console output keeps the order of the tests.
+				decoder := json.NewDecoder(console)
+				for _, test := range tests {
+					var got allure.Test
+					if err := decoder.Decode(&got); err != nil {
+						t.Fatalf("json.Decoder.Decode: %v", err)
+					}
+
+					if got.UUID != test.UUID {
+						t.Fatalf("got: %s, want: %s", got.UUID, test.UUID)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestWriter_WriteReportError(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tests := []allure.Test{{UUID: "1"}, {UUID: "2"}, {UUID: "3"}}
+	w := NewWriter(WriteToFile(dir), WriteReportTo(&failingWriter{writes: 2}), WithWorkers(2))
+
+	if err := w.WriteReport(context.Background(), tests); err == nil {
+		t.Fatalf("got: nil, want: write error")
+	}
+
+	// The files of the tests written before the error are complete when WriteReport returns.
+	expected := []string{"1" + resultFileSuffix, "2" + resultFileSuffix}
+	if diff := cmp.Diff(expected, dirEntries(t, dir)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestWriter_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		write func(ctx context.Context, w Writer) error
+	}{
+		{
+			name: "test_write_report",
+			write: func(ctx context.Context, w Writer) error {
+				return w.WriteReport(ctx, []allure.Test{{UUID: "1"}})
+			},
+		},
+		{
+			name: "test_write_attachments",
+			write: func(ctx context.Context, w Writer) error {
+				return w.WriteAttachments(ctx, []Attachment{{Source: "a-attachment.txt", Body: []byte("log")}})
+			},
+		},
+		{
+			name: "test_write_environment",
+			write: func(ctx context.Context, w Writer) error {
+				return w.WriteEnvironment(ctx, map[string]string{"go.version": "go1.20"})
+			},
+		},
+		{
+			name: "test_flush",
+			write: func(ctx context.Context, w Writer) error {
+				return w.Flush(ctx)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				dir := filepath.Join(t.TempDir(), "allure-results")
+				w := NewWriter(WriteToFile(dir), WriteJUnitTo(filepath.Join(dir, "junit.xml")))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				if err := tc.write(ctx, w); !errors.Is(err, context.Canceled) {
+					t.Fatalf("got: %v, want: %v", err, context.Canceled)
+				}
+
+				if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("got: %v, want: nothing is written", err)
+				}
+			},
+		)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		existing []byte
+		body     []byte
+		fsync    bool
+	}{
+		{
+			name: "test_new_file",
+			body: []byte("body"),
+		},
+		{
+			name:     "test_replace_file",
+			existing: []byte("a much longer previous body"),
+			body:     []byte("body"),
+			fsync:    true,
+		},
+		{
+			name: "test_empty_body",
+			body: []byte{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				dir := t.TempDir()
+				pth := filepath.Join(dir, "1-result.json")
+				if tc.existing != nil {
+					if err := os.WriteFile(pth, tc.existing, 0o600); err != nil {
+						t.Fatalf("os.WriteFile: %v", err)
+					}
+				}
+
+				if err := writeFile(pth, tc.body, tc.fsync); err != nil {
+					t.Fatalf("writeFile: %v", err)
+				}
+
+				got, err := os.ReadFile(pth)
+				if err != nil {
+					t.Fatalf("os.ReadFile: %v", err)
+				}
+
+				if diff := cmp.Diff(tc.body, got); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				info, err := os.Stat(pth)
+				if err != nil {
+					t.Fatalf("os.Stat: %v", err)
+				}
+
+				if info.Mode().Perm() != 0o644 {
+					t.Errorf("got: %v, want: %v", info.Mode().Perm(), os.FileMode(0o644))
+				}
+
+				if diff := cmp.Diff([]string{"1-result.json"}, dirEntries(t, dir)); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+func TestWriteFile_Error(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	// The rename fails because the target is a directory, the temporary file must be removed.
+	pth := filepath.Join(dir, "1-result.json")
+	if err := os.MkdirAll(filepath.Join(pth, "nested"), 0o755); err != nil {
+		t.Fatalf("os.MkdirAll: %v", err)
+	}
+
+	if err := writeFile(pth, []byte("body"), false); err == nil {
+		t.Fatalf("got: nil, want: rename error")
+	}
+
+	if diff := cmp.Diff([]string{"1-result.json"}, dirEntries(t, dir)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	if err := writeFile(filepath.Join(dir, "missing", "1-result.json"), []byte("body"), false); err == nil {
+		t.Fatalf("got: nil, want: create error")
+	}
+}
@@ -0,0 +1,167 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrNotRepository is returned by Open if the directory is not inside a git work tree or git is not installed.
+var ErrNotRepository = errors.New("not a git repository")
+
+// branchEnvs name the branch in CI systems checking out a detached HEAD.
+var branchEnvs = []string{"GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_REF_NAME", "BRANCH_NAME"}
+
+// Repository describes the git work tree and the checked out commit.
+type Repository struct {
+	Root   string
+	Commit string
+	Branch string
+	URL    string
+
+	mu     sync.Mutex
+	blames map[string][]string
+}
+
+// Root returns the top level directory of the work tree containing dir.
+func Root(ctx context.Context, dir string) (string, error) {
+	root, err := run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrNotRepository, err)
+	}
+
+	return root, nil
+}
+
+// Open reads the commit, the branch and the origin URL of the repository containing dir.
+func Open(ctx context.Context, dir string) (*Repository, error) {
+	root, err := Root(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &Repository{Root: root, blames: make(map[string][]string)}
+
+	// An empty repository has no HEAD and a repository without a remote has no origin, both are optional.
+	repo.Commit, _ = run(ctx, root, "rev-parse", "HEAD")
+	repo.Branch, _ = run(ctx, root, "rev-parse", "--abbrev-ref", "HEAD")
+	if repo.Branch == "" || repo.Branch == "HEAD" {
+		repo.Branch = ""
+		for _, env := range branchEnvs {
+			if v := os.Getenv(env); v != "" {
+				repo.Branch = v
+				break
+			}
+		}
+	}
+
+	if origin, _ := run(ctx, root, "config", "--get", "remote.origin.url"); origin != "" {
+		repo.URL = stripCredentials(origin)
+	}
+
+	return repo, nil
+}
+
+// Author returns the author of the last change of the line, the file is blamed once and cached.
+func (r *Repository) Author(ctx context.Context, pth string, line int) (string, error) {
+	r.mu.Lock()
+	authors, ok := r.blames[pth]
+	r.mu.Unlock()
+
+	if !ok {
+		var err error
+		if authors, err = r.blame(ctx, pth); err != nil {
+			return "", err
+		}
+
+		r.mu.Lock()
+		r.blames[pth] = authors
+		r.mu.Unlock()
+	}
+
+	if line < 1 || line > len(authors) {
+		return "", nil
+	}
+
+	return authors[line-1], nil
+}
+
+// Environment returns the git entries of environment.properties.
+func (r *Repository) Environment() map[string]string {
+	env := make(map[string]string)
+	for key, value := range map[string]string{"git.commit": r.Commit, "git.branch": r.Branch, "git.url": r.URL} {
+		if value != "" {
+			env[key] = value
+		}
+	}
+
+	return env
+}
+
+// blame returns the author of every line of the file.
+func (r *Repository) blame(ctx context.Context, pth string) ([]string, error) {
+	if rel, err := filepath.Rel(r.Root, pth); err == nil {
+		pth = rel
+	}
+
+	out, err := run(ctx, r.Root, "blame", "--line-porcelain", "--", pth)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBlame(out)
+}
+
+// parseBlame returns the authors of the lines of git blame --line-porcelain output.
+func parseBlame(out string) ([]string, error) {
+	var authors []string
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if author, ok := strings.CutPrefix(scanner.Text(), "author "); ok {
+			authors = append(authors, author)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("bufio.Scanner: %w", err)
+	}
+
+	return authors, nil
+}
+
+// stripCredentials removes user info from http remotes, which may contain access tokens.
+func stripCredentials(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return remote
+	}
+
+	u.User = nil
+
+	return u.String()
+}
+
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("command Run git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
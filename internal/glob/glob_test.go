@@ -0,0 +1,81 @@
+package glob
+
+import (
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		pattern  string
+		matches  []string
+		excludes []string
+	}{
+		{
+			name:     "test_literal",
+			pattern:  "internal/api",
+			matches:  []string{"internal/api"},
+			excludes: []string{"internal/api/v1", "internal/apis", "pkg/internal/api"},
+		},
+		{
+			name:     "test_star_within_segment",
+			pattern:  "services/*/api",
+			matches:  []string{"services/billing/api", "services//api"},
+			excludes: []string{"services/billing/v1/api", "services/billing/api/v1"},
+		},
+		{
+			name:     "test_double_star_crosses_segments",
+			pattern:  "**/e2e",
+			matches:  []string{"a/e2e", "a/b/c/e2e"},
+			excludes: []string{"e2e", "a/e2e/b"},
+		},
+		{
+			name:     "test_trailing_double_star",
+			pattern:  "services/**",
+			matches:  []string{"services/", "services/api", "services/billing/api"},
+			excludes: []string{"services", "internal/services/api"},
+		},
+		{
+			name:     "test_question_mark",
+			pattern:  "v?/api",
+			matches:  []string{"v1/api", "v2/api"},
+			excludes: []string{"v10/api", "v/api", "v//api"},
+		},
+		{
+			name:     "test_regexp_meta",
+			pattern:  "pkg.v2/(a)+[b]",
+			matches:  []string{"pkg.v2/(a)+[b]"},
+			excludes: []string{"pkgxv2/(a)+[b]", "pkg.v2/aa[b]"},
+		},
+		{
+			name:     "test_non_ascii",
+			pattern:  "тесты/*",
+			matches:  []string{"тесты/api"},
+			excludes: []string{"tests/api"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				re := Compile(tc.pattern)
+				for _, pth := range tc.matches {
+					if !re.MatchString(pth) {
+						t.Errorf("%q: got: no match, want: match of %q", tc.pattern, pth)
+					}
+				}
+
+				for _, pth := range tc.excludes {
+					if re.MatchString(pth) {
+						t.Errorf("%q: got: match, want: no match of %q", tc.pattern, pth)
+					}
+				}
+			},
+		)
+	}
+}
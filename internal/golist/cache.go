@@ -0,0 +1,121 @@
+package golist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/robotomize/go-allure/internal/cache"
+)
+
+// goEnvVars are the go env variables changing the packages go list returns.
+var goEnvVars = []string{"GOOS", "GOARCH", "CGO_ENABLED", "GOFLAGS", "GOWORK", "GOVERSION"}
+
+// WithCache caches the packages listed by import paths in the directory until their directories, files,
+// go.mod, go.work or vendor/modules.txt change. The key identifies the listing, e.g. the go list flags.
+func WithCache(retriever PackageRetriever, c *cache.Cache, dir string, key ...string) PackageRetriever {
+	return &cachedRetriever{retriever: retriever, cache: c, dir: dir, key: key}
+}
+
+type cachedRetriever struct {
+	retriever PackageRetriever
+	cache     *cache.Cache
+	dir       string
+	key       []string
+}
+
+type cachedPackages struct {
+	Stamps   map[string]cache.Stamp `json:"stamps"`
+	Packages []Package              `json:"packages"`
+}
+
+// Retrieve returns the cached packages if none of their files changed. Listing all packages is never cached,
+// since new packages could not be detected.
+func (r *cachedRetriever) Retrieve(ctx context.Context, importPaths ...string) ([]Package, error) {
+	if len(importPaths) == 0 {
+		return r.retriever.Retrieve(ctx)
+	}
+
+	// Without the go env the listing cannot be identified, the retriever reports why go does not run.
+	env, err := goEnv(ctx, r.dir)
+	if err != nil {
+		return r.retriever.Retrieve(ctx, importPaths...)
+	}
+
+	sorted := append([]string(nil), importPaths...)
+	sort.Strings(sorted)
+
+	parts := []string{"golist", r.dir}
+	for _, name := range goEnvVars {
+		parts = append(parts, env[name])
+	}
+
+	key := cache.Key(append(append(parts, r.key...), sorted...)...)
+
+	var entry cachedPackages
+	if r.cache.Get(key, &entry) && cache.Valid(entry.Stamps) {
+		return entry.Packages, nil
+	}
+
+	packages, err := r.retriever.Retrieve(ctx, importPaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Packages that could not be found may appear later, such a listing is not cached.
+	if len(packages) < len(sorted) {
+		return packages, nil
+	}
+
+	// The go.work and vendor/modules.txt files are stamped even if they are missing, creating them
+	// changes the listing too.
+	var pths []string
+	if gowork := env["GOWORK"]; gowork != "" && gowork != "off" {
+		pths = append(pths, gowork, filepath.Join(filepath.Dir(gowork), "vendor", "modules.txt"))
+	}
+
+	for _, pkg := range packages {
+		pths = append(pths, pkg.Dir, pkg.Module.GoMod)
+		if pkg.Module.GoMod != "" {
+			pths = append(pths, filepath.Join(filepath.Dir(pkg.Module.GoMod), "vendor", "modules.txt"))
+		}
+
+		for _, files := range [][]string{pkg.GoFiles, pkg.TestGoFiles, pkg.XTestGoFiles, pkg.IgnoredGoFiles} {
+			for _, file := range files {
+				pths = append(pths, filepath.Join(pkg.Dir, file))
+			}
+		}
+	}
+
+	if entry.Stamps, err = cache.Stamps(pths...); err == nil {
+		entry.Packages = packages
+		_ = r.cache.Put(key, entry)
+	}
+
+	return packages, nil
+}
+
+// goEnv returns the values of the go env variables in the directory.
+func goEnv(ctx context.Context, dir string) (map[string]string, error) {
+	stderr := bytes.NewBuffer(make([]byte, 0))
+	cmd := exec.CommandContext(ctx, "go", append([]string{"env", "-json"}, goEnvVars...)...)
+	cmd.Dir = dir
+	cmd.Stderr = stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("command Run go env: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	env := make(map[string]string, len(goEnvVars))
+	if err = json.Unmarshal(out, &env); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	return env, nil
+}
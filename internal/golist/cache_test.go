@@ -0,0 +1,196 @@
+package golist
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/robotomize/go-allure/internal/cache"
+)
+
+// countingRetriever returns the packages found among its packages and counts the calls.
+type countingRetriever struct {
+	packages []Package
+	calls    int
+}
+
+func (r *countingRetriever) Retrieve(_ context.Context, importPaths ...string) ([]Package, error) {
+	r.calls++
+
+	var packages []Package
+	for _, pkg := range r.packages {
+		for _, importPath := range importPaths {
+			if pkg.ImportPath == importPath {
+				packages = append(packages, pkg)
+			}
+		}
+	}
+
+	return packages, nil
+}
+
+// TestWithCache - tests that the listing is cached until the files, the go env or the workspace change.
+// It changes the go env, so it does not run in parallel.
+func TestWithCache(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOWORK", "")
+	t.Setenv("GOOS", runtime.GOOS)
+	t.Setenv("GOARCH", runtime.GOARCH)
+	t.Setenv("CGO_ENABLED", "0")
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("filepath.EvalSymlinks: %v", err)
+	}
+
+	writeFiles(
+		t, dir, map[string]string{
+			"go.mod":    "module example.com/a\n\ngo 1.20\n",
+			"a.go":      "package a\n",
+			"a_test.go": "package a\n",
+		},
+	)
+
+	retriever := &countingRetriever{
+		packages: []Package{
+			{
+				Dir:         dir,
+				ImportPath:  "example.com/a",
+				GoFiles:     []string{"a.go"},
+				TestGoFiles: []string{"a_test.go"},
+				Module:      Module{Path: "example.com/a", Dir: dir, GoMod: filepath.Join(dir, "go.mod")},
+			},
+		},
+	}
+
+	cached := WithCache(retriever, cache.New(t.TempDir()), dir, "-tags", "integration")
+
+	otherOS := "windows"
+	if runtime.GOOS == otherOS {
+		otherOS = "linux"
+	}
+
+	steps := []struct {
+		name        string
+		change      func(t *testing.T)
+		importPaths []string
+		expected    int
+	}{
+		{
+			name:        "test_first_listing",
+			importPaths: []string{"example.com/a"},
+			expected:    1,
+		},
+		{
+			name:        "test_cached",
+			importPaths: []string{"example.com/a"},
+			expected:    1,
+		},
+		{
+			name:     "test_all_packages_not_cached",
+			expected: 2,
+		},
+		{
+			name:        "test_missing_package_not_cached",
+			importPaths: []string{"example.com/a", "example.com/missing"},
+			expected:    3,
+		},
+		{
+			name:        "test_missing_package_not_cached_again",
+			importPaths: []string{"example.com/missing", "example.com/a"},
+			expected:    4,
+		},
+		{
+			name: "test_changed_test_file",
+			change: func(t *testing.T) {
+				writeFiles(t, dir, map[string]string{"a_test.go": "package a\n\nfunc TestA(t *testing.T) {}\n"})
+			},
+			importPaths: []string{"example.com/a"},
+			expected:    5,
+		},
+		{
+			name:        "test_cached_after_change",
+			importPaths: []string{"example.com/a"},
+			expected:    5,
+		},
+		{
+			name: "test_created_vendor",
+			change: func(t *testing.T) {
+				writeFiles(t, dir, map[string]string{"vendor/modules.txt": "# example.com/dep v1.0.0\n"})
+			},
+			importPaths: []string{"example.com/a"},
+			expected:    6,
+		},
+		{
+			name:        "test_goos",
+			change:      func(t *testing.T) { t.Setenv("GOOS", otherOS) },
+			importPaths: []string{"example.com/a"},
+			expected:    7,
+		},
+		{
+			name:        "test_cgo_enabled",
+			change:      func(t *testing.T) { t.Setenv("CGO_ENABLED", "1") },
+			importPaths: []string{"example.com/a"},
+			expected:    8,
+		},
+		{
+			name: "test_created_go_work",
+			change: func(t *testing.T) {
+				writeFiles(t, dir, map[string]string{"go.work": "go 1.20\n\nuse .\n"})
+			},
+			importPaths: []string{"example.com/a"},
+			expected:    9,
+		},
+		{
+			name: "test_changed_go_work",
+			change: func(t *testing.T) {
+				writeFiles(t, dir, map[string]string{"go.work": "go 1.20\n\nuse (\n\t.\n)\n"})
+			},
+			importPaths: []string{"example.com/a"},
+			expected:    10,
+		},
+		{
+			name:        "test_gowork_off",
+			change:      func(t *testing.T) { t.Setenv("GOWORK", "off") },
+			importPaths: []string{"example.com/a"},
+			expected:    11,
+		},
+		{
+			name:        "test_cached_at_last",
+			importPaths: []string{"example.com/a"},
+			expected:    11,
+		},
+	}
+
+	for _, step := range steps {
+		if step.change != nil {
+			step.change(t)
+		}
+
+		if _, err = cached.Retrieve(context.Background(), step.importPaths...); err != nil {
+			t.Fatalf("%s: Retrieve: %v", step.name, err)
+		}
+
+		if retriever.calls != step.expected {
+			t.Errorf("%s: got: %d calls, want: %d", step.name, retriever.calls, step.expected)
+		}
+	}
+}
+
+func TestWithCache_NoGo(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	// The listing is not cached without the go env.
+	retriever := &countingRetriever{packages: []Package{{Dir: t.TempDir(), ImportPath: "example.com/a"}}}
+	cached := WithCache(retriever, cache.New(t.TempDir()), t.TempDir())
+	for i := 0; i < 2; i++ {
+		if _, err := cached.Retrieve(context.Background(), "example.com/a"); err != nil {
+			t.Fatalf("Retrieve: %v", err)
+		}
+	}
+
+	if retriever.calls != 2 {
+		t.Errorf("got: %d calls, want: %d", retriever.calls, 2)
+	}
+}
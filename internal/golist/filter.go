@@ -0,0 +1,75 @@
+package golist
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/robotomize/go-allure/internal/glob"
+)
+
+// WithPathFilter keeps the packages whose directory relative to the root matches any of the include globs
+// and none of the exclude globs. A glob also matches everything under a matching directory,
+// so internal/legacy excludes internal/legacy/api too.
+func WithPathFilter(retriever PackageRetriever, root string, include, exclude []string) PackageRetriever {
+	compile := func(patterns []string) []*regexp.Regexp {
+		compiled := make([]*regexp.Regexp, 0, len(patterns))
+		for _, pattern := range patterns {
+			if pattern = strings.Trim(strings.TrimSpace(pattern), "/"); pattern != "" {
+				compiled = append(compiled, glob.Compile(pattern))
+			}
+		}
+
+		return compiled
+	}
+
+	return &filterRetriever{retriever: retriever, root: root, include: compile(include), exclude: compile(exclude)}
+}
+
+type filterRetriever struct {
+	retriever PackageRetriever
+	root      string
+	include   []*regexp.Regexp
+	exclude   []*regexp.Regexp
+}
+
+func (r *filterRetriever) Retrieve(ctx context.Context, importPaths ...string) ([]Package, error) {
+	packages, err := r.retriever.Retrieve(ctx, importPaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]Package, 0, len(packages))
+	for _, pkg := range packages {
+		rel, err := filepath.Rel(r.root, pkg.Dir)
+		if err != nil {
+			continue
+		}
+
+		rel = filepath.ToSlash(rel)
+		if (len(r.include) == 0 || matchDir(r.include, rel)) && !matchDir(r.exclude, rel) {
+			filtered = append(filtered, pkg)
+		}
+	}
+
+	return filtered, nil
+}
+
+// matchDir reports whether the directory or one of its parents matches any of the patterns.
+func matchDir(patterns []*regexp.Regexp, dir string) bool {
+	for {
+		for _, re := range patterns {
+			if re.MatchString(dir) {
+				return true
+			}
+		}
+
+		pos := strings.LastIndexByte(dir, '/')
+		if pos < 0 {
+			return false
+		}
+
+		dir = dir[:pos]
+	}
+}
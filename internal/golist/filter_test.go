@@ -0,0 +1,70 @@
+package golist
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type staticRetriever []Package
+
+func (r staticRetriever) Retrieve(context.Context, ...string) ([]Package, error) {
+	return r, nil
+}
+
+func TestWithPathFilter(t *testing.T) {
+	t.Parallel()
+
+	packages := staticRetriever{
+		{Dir: "/repo", ImportPath: "acme"},
+		{Dir: "/repo/internal/api", ImportPath: "acme/internal/api"},
+		{Dir: "/repo/internal/legacy/db", ImportPath: "acme/internal/legacy/db"},
+		{Dir: "/repo/services/billing/api", ImportPath: "acme/services/billing/api"},
+	}
+
+	testCases := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{
+			name:     "test_no_filters",
+			expected: []string{"acme", "acme/internal/api", "acme/internal/legacy/db", "acme/services/billing/api"},
+		},
+		{
+			name:     "test_include_parent_directory",
+			include:  []string{"internal"},
+			expected: []string{"acme/internal/api", "acme/internal/legacy/db"},
+		},
+		{
+			name:     "test_exclude_glob",
+			exclude:  []string{"internal/legacy", "services/*/api"},
+			expected: []string{"acme", "acme/internal/api"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				got, err := WithPathFilter(packages, "/repo", tc.include, tc.exclude).Retrieve(context.Background())
+				if err != nil {
+					t.Fatalf("Retrieve: %v", err)
+				}
+
+				importPaths := make([]string, 0, len(got))
+				for _, pkg := range got {
+					importPaths = append(importPaths, pkg.ImportPath)
+				}
+
+				if diff := cmp.Diff(tc.expected, importPaths); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
@@ -1,36 +1,35 @@
 package golist
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strings"
-
-	"golang.org/x/sync/errgroup"
 )
 
 type Package struct {
-	Dir            string   `json:"Dir"`
-	ImportPath     string   `json:"ImportPath"`
-	Name           string   `json:"Name"`
-	Root           string   `json:"Root"`
-	Module         Module   `json:"Module"`
-	Match          []string `json:"Match"`
-	Stale          bool     `json:"Stale"`
-	StaleReason    string   `json:"StaleReason"`
-	GoFiles        []string `json:"GoFiles"`
-	TestGoFiles    []string `json:"TestGoFiles"`
-	XTestGoFiles   []string `json:"XTestGoFiles"`
-	IgnoredGoFiles []string `json:"IgnoredGoFiles"`
-	Imports        []string `json:"Imports"`
-	Deps           []string `json:"Deps"`
+	Dir            string        `json:"Dir"`
+	ImportPath     string        `json:"ImportPath"`
+	Name           string        `json:"Name"`
+	Root           string        `json:"Root"`
+	Module         Module        `json:"Module"`
+	Match          []string      `json:"Match"`
+	Stale          bool          `json:"Stale"`
+	StaleReason    string        `json:"StaleReason"`
+	GoFiles        []string      `json:"GoFiles"`
+	TestGoFiles    []string      `json:"TestGoFiles"`
+	XTestGoFiles   []string      `json:"XTestGoFiles"`
+	IgnoredGoFiles []string      `json:"IgnoredGoFiles"`
+	Imports        []string      `json:"Imports"`
+	Deps           []string      `json:"Deps"`
+	Error          *PackageError `json:"Error"`
 }
 
 type Module struct {
@@ -41,31 +40,141 @@ type Module struct {
 	GoVersion string `json:"GoVersion"`
 }
 
+// ErrUnavailable is returned if the Go toolchain is not installed or the directory is not in a module.
+// Other go list errors, e.g. of invalid flags or a broken go.mod, are returned unchanged.
+var ErrUnavailable = errors.New("go list is unavailable")
+
+// missingModuleErrors are the go list messages of a directory outside of a module.
+var missingModuleErrors = []string{
+	"go.mod file not found",
+	"cannot find main module",
+	"does not contain main module",
+}
+
+type PackageError struct {
+	Err string `json:"Err"`
+}
+
+// skipDirs are never searched for go modules.
+var skipDirs = map[string]struct{}{"vendor": {}, "testdata": {}, "node_modules": {}}
+
 // DirPackages - walk fs and collects all go packages found on directory.
+// In a go.work workspace the packages of the workspace modules under the root directory are listed
+// with a single go list call.
 func DirPackages(ctx context.Context, dfs FS, args ...string) ([]Package, error) {
+	if inWorkspace(dfs.RootDir()) {
+		patterns, err := workspacePatterns(ctx, dfs.RootDir())
+		if err != nil {
+			return nil, fmt.Errorf("workspacePatterns: %w", err)
+		}
+
+		packages, err := listPackages(ctx, dfs.RootDir(), patterns, args...)
+		if err != nil {
+			return nil, fmt.Errorf("listPackages: %w", err)
+		}
+
+		return packages, nil
+	}
+
+	modules, err := findModules(dfs)
+	if err != nil {
+		return nil, fmt.Errorf("findModules: %w", err)
+	}
+
 	packages := make([]Package, 0)
+	for _, m := range modules {
+		list, err := listPackages(ctx, m.dir, []string{"./..."}, args...)
+		if err != nil {
+			return nil, fmt.Errorf("listPackages: %w", err)
+		}
+
+		packages = append(packages, list...)
+	}
+
+	return packages, nil
+}
+
+// ImportPathPackages lists only the packages with the given import paths. Each package is listed in the module
+// with the longest matching module path, packages that cannot be loaded are skipped.
+func ImportPathPackages(ctx context.Context, dfs FS, importPaths []string, args ...string) ([]Package, error) {
+	args = append([]string{"-e"}, args...)
+
+	groups := make(map[string][]string)
+	if inWorkspace(dfs.RootDir()) {
+		groups[dfs.RootDir()] = importPaths
+	} else {
+		modules, err := findModules(dfs)
+		if err != nil {
+			return nil, fmt.Errorf("findModules: %w", err)
+		}
+
+		for _, importPath := range importPaths {
+			var found module
+			for _, m := range modules {
+				inModule := importPath == m.path || strings.HasPrefix(importPath, m.path+"/")
+				if inModule && len(m.path) > len(found.path) {
+					found = m
+				}
+			}
+
+			if found.dir != "" {
+				groups[found.dir] = append(groups[found.dir], importPath)
+			}
+		}
+	}
+
+	packages := make([]Package, 0, len(importPaths))
+	for dir, group := range groups {
+		list, err := listPackages(ctx, dir, group, args...)
+		if err != nil {
+			return nil, fmt.Errorf("listPackages: %w", err)
+		}
+
+		for _, pkg := range list {
+			if pkg.Error == nil && pkg.Dir != "" {
+				packages = append(packages, pkg)
+			}
+		}
+	}
+
+	return packages, nil
+}
+
+type module struct {
+	dir  string
+	path string
+}
+
+// findModules walks fs and collects the directories and paths of go modules.
+func findModules(dfs FS) ([]module, error) {
+	var modules []module
 
 	// Use fs.WalkDir to recursively walk through the file system and detect Go modules.
 	if err := fs.WalkDir(
 		dfs, ".", func(pth string, entry fs.DirEntry, err error) error {
-			// Skip hidden directories and files, and non-directories.
-			skip := strings.HasPrefix(entry.Name(), ".") || strings.HasPrefix(entry.Name(), "..") || entry.IsDir()
-			if skip {
-				return nil
+			if err != nil {
+				return err
 			}
 
-			if entry.Name() == "go.mod" {
-				d, _ := filepath.Split(pth)
-				if d == "" {
-					d = dfs.RootDir()
+			// Skip hidden directories, vendored and test data directories, they may contain go.mod files
+			// which are not a part of the build.
+			if entry.IsDir() {
+				_, skip := skipDirs[entry.Name()]
+				if pth != "." && (skip || strings.HasPrefix(entry.Name(), ".")) {
+					return fs.SkipDir
 				}
 
-				list, err := listPackages(ctx, d, args...)
+				return nil
+			}
+
+			if entry.Name() == "go.mod" {
+				b, err := fs.ReadFile(dfs, pth)
 				if err != nil {
-					return fmt.Errorf("listPackages: %w", err)
+					return fmt.Errorf("fs.ReadFile: %w", err)
 				}
 
-				packages = append(packages, list...)
+				d, _ := filepath.Split(pth)
+				modules = append(modules, module{dir: filepath.Join(dfs.RootDir(), d), path: modulePath(b)})
 			}
 
 			return nil
@@ -74,114 +183,139 @@ func DirPackages(ctx context.Context, dfs FS, args ...string) ([]Package, error)
 		return nil, fmt.Errorf("fs.WalkDir: %w", err)
 	}
 
-	return packages, nil
+	return modules, nil
 }
 
-// list go packages in given directory.
-func listPackages(ctx context.Context, dir string, args ...string) ([]Package, error) {
-	var pkgNames []string
+// modulePath returns the module path of the go.mod file.
+func modulePath(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
 
-	// Use the goList function to list all packages in the directory, append them to the pkgNames slice.
-	packagesBuf, err := goList(ctx, dir, append(args, "./..."))
-	if err != nil {
-		return nil, err
+	return ""
+}
+
+// inWorkspace reports whether go runs in workspace mode in the directory: GOWORK names a go.work file
+// or a go.work file is found in the directory or its parents.
+func inWorkspace(dir string) bool {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return false
+	case "":
+	default:
+		return true
 	}
 
-	// Scan the buffer and collect package names
-	scanner := bufio.NewScanner(packagesBuf)
-	for scanner.Scan() {
-		if err = scanner.Err(); err != nil {
-			return nil, fmt.Errorf("bufio.NewScaner.Err: %w", err)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.work")); err == nil {
+			return true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
 		}
 
-		pkgNames = append(pkgNames, scanner.Text())
+		dir = parent
 	}
+}
 
-	goPkgs := make([]Package, 0, len(pkgNames))
+// workspacePatterns returns a package pattern for each module of the go.work workspace under the directory.
+func workspacePatterns(ctx context.Context, dir string) ([]string, error) {
+	var patterns []string
+	if err := goList(
+		ctx, dir, []string{"-m", "-f", "{{.Dir}}"}, func(r io.Reader) error {
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return fmt.Errorf("io.ReadAll: %w", err)
+			}
 
-	// Use errgroup to limit the number of concurrent goroutines.
-	// Declare a channel to hold the parsed Package objects, and a wait group.
-	ch := make(chan Package)
-	closeCh := make(chan struct{})
-	wg, grpCtx := errgroup.WithContext(ctx)
-	wg.SetLimit(runtime.NumCPU())
+			for _, moduleDir := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+				if rel, err := filepath.Rel(dir, moduleDir); err == nil && !strings.HasPrefix(rel, "..") {
+					patterns = append(patterns, filepath.Join(moduleDir, "..."))
+				}
+			}
 
-	// Start a goroutine to append the parsed Package objects to the goPkgs slice.
-	go func() {
-		defer close(closeCh)
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
 
-		for pkg := range ch {
-			goPkgs = append(goPkgs, pkg)
-		}
-	}()
-
-	// Loop through the package names and use errgroup to parse the package data concurrently.
-OuterLoop:
-	for _, pkgName := range pkgNames {
-		pkg := pkgName
-
-		// Check if the group context is done to break out of the loop.
-		select {
-		case <-grpCtx.Done():
-			break OuterLoop
-		default:
-		}
+	return patterns, nil
+}
 
-		// Use errgroup to run the goList command on the package name and parse the returned JSON data.
-		wg.Go(
-			func() error {
-				pkgArgs := append([]string{"-json"}, args...)
-				pkgArgs = append(pkgArgs, pkg)
+// listPackages lists go packages matching the patterns with a single go list call decoding its JSON stream.
+func listPackages(ctx context.Context, dir string, patterns []string, args ...string) ([]Package, error) {
+	goPkgs := make([]Package, 0)
 
-				packageBuf, pkgErr := goList(grpCtx, dir, pkgArgs)
-				if pkgErr != nil {
-					return fmt.Errorf("goList: %w", pkgErr)
-				}
+	listArgs := append([]string{"-json"}, args...)
+	listArgs = append(listArgs, patterns...)
 
+	if err := goList(
+		ctx, dir, listArgs, func(r io.Reader) error {
+			decoder := json.NewDecoder(r)
+			for {
 				var goPkg Package
-				if dErr := json.NewDecoder(packageBuf).Decode(&goPkg); dErr != nil {
-					return fmt.Errorf("json.NewDecoder.Decode: %w", dErr)
-				}
-
-				// Send the parsed Package object to the channel.
-				ch <- goPkg
+				if err := decoder.Decode(&goPkg); err != nil {
+					if errors.Is(err, io.EOF) {
+						return nil
+					}
 
-				return nil
-			},
-		)
-	}
+					return fmt.Errorf("json.NewDecoder.Decode: %w", err)
+				}
 
-	if err = wg.Wait(); err != nil {
+				goPkgs = append(goPkgs, goPkg)
+			}
+		},
+	); err != nil {
 		return nil, err
 	}
 
-	close(ch)
-
-	// Check if the context is done to return early.
-	// Otherwise, wait for the channel to close and return the slice of Package objects.
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case <-closeCh:
-	}
-
 	return goPkgs, nil
 }
 
-func goList(ctx context.Context, dir string, args []string) (io.Reader, error) {
-	const bufSize = 4096
-
-	b := bytes.NewBuffer(make([]byte, 0, bufSize))
+// goList runs go list in the directory and passes its output to the decode function while it is running.
+func goList(ctx context.Context, dir string, args []string, decode func(r io.Reader) error) error {
+	stderr := bytes.NewBuffer(make([]byte, 0))
 	cmd := exec.CommandContext(ctx, "go", append([]string{"list"}, args...)...)
-	cmd.Stdout = b
 	cmd.Dir = dir
-
+	cmd.Stderr = stderr
 	cmd.Stdin = strings.NewReader("")
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("command Run go list %s: %w", strings.Join(args, " "), err)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("command StdoutPipe: %w", err)
+	}
+
+	if err = cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			err = fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+
+		return fmt.Errorf("command Start go list %s: %w", strings.Join(args, " "), err)
+	}
+
+	decodeErr := decode(stdout)
+	if decodeErr != nil {
+		// Drain the output to let go list exit.
+		_, _ = io.Copy(io.Discard, stdout)
 	}
 
-	b1 := bytes.NewBuffer(b.Bytes())
+	if err = cmd.Wait(); err != nil {
+		for _, msg := range missingModuleErrors {
+			if strings.Contains(stderr.String(), msg) {
+				err = fmt.Errorf("%w: %w", ErrUnavailable, err)
+				break
+			}
+		}
+
+		return fmt.Errorf(
+			"command Run go list %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()),
+		)
+	}
 
-	return b1, nil
+	return decodeErr
 }
@@ -0,0 +1,439 @@
+package golist
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/fs"
+)
+
+// TestDirPackages - tests listing of separate modules and go.work workspaces with the go flags.
+// It changes the environment of go list, so it does not run in parallel.
+func TestDirPackages(t *testing.T) {
+	modules := map[string]string{
+		"a/go.mod":   "module example.com/a\n\ngo 1.20\n",
+		"a/a.go":     "package a\n",
+		"a/extra.go": "//go:build extra\n\npackage a\n",
+		"b/go.mod":   "module example.com/b\n\ngo 1.20\n",
+		"b/b.go":     "package b\n",
+		"b/c/c.go":   "package c\n",
+		"d/go.mod":   "module example.com/d\n\ngo 1.20\n",
+		"d/d.go":     "package d\n",
+	}
+
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		env      map[string]string
+		args     []string
+		expected map[string][]string
+	}{
+		{
+			name: "test_modules",
+			expected: map[string][]string{
+				"example.com/a":   {"a.go"},
+				"example.com/b":   {"b.go"},
+				"example.com/b/c": {"c.go"},
+				"example.com/d":   {"d.go"},
+			},
+		},
+		{
+			name:  "test_workspace",
+			files: map[string]string{"go.work": "go 1.20\n\nuse (\n\t./a\n\t./b\n)\n"},
+			expected: map[string][]string{
+				"example.com/a":   {"a.go"},
+				"example.com/b":   {"b.go"},
+				"example.com/b/c": {"c.go"},
+			},
+		},
+		{
+			name: "test_goflags",
+			env:  map[string]string{"GOFLAGS": "-tags=extra"},
+			expected: map[string][]string{
+				"example.com/a":   {"a.go", "extra.go"},
+				"example.com/b":   {"b.go"},
+				"example.com/b/c": {"c.go"},
+				"example.com/d":   {"d.go"},
+			},
+		},
+		{
+			name:  "test_args",
+			files: map[string]string{"go.work": "go 1.20\n\nuse ./a\n"},
+			args:  []string{"-tags", "extra"},
+			expected: map[string][]string{
+				"example.com/a": {"a.go", "extra.go"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Setenv("GOFLAGS", "")
+				t.Setenv("GOWORK", "")
+				t.Setenv("GOPROXY", "off")
+				for key, value := range tc.env {
+					t.Setenv(key, value)
+				}
+
+				dir := t.TempDir()
+				writeFiles(t, dir, modules)
+				writeFiles(t, dir, tc.files)
+
+				packages, err := DirPackages(context.Background(), fs.New(dir), tc.args...)
+				if err != nil {
+					t.Fatalf("DirPackages: %v", err)
+				}
+
+				got := make(map[string][]string, len(packages))
+				for _, pkg := range packages {
+					got[pkg.ImportPath] = pkg.GoFiles
+				}
+
+				if diff := cmp.Diff(tc.expected, got); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+// TestListPackages_Error - tests that the go flags reach go list, its stderr is a part of the error and
+// only a missing toolchain or module makes go list unavailable.
+// It changes the environment of go list, so it does not run in parallel.
+func TestListPackages_Error(t *testing.T) {
+	// The required module is not vendored, go list fails in the vendor mode only.
+	module := map[string]string{
+		"go.mod": "module example.com/a\n\ngo 1.20\n\nrequire example.com/dep v1.0.0\n",
+		"a.go":   "package a\n",
+	}
+
+	testCases := []struct {
+		name        string
+		files       map[string]string
+		env         map[string]string
+		args        []string
+		expected    string
+		unavailable bool
+	}{
+		{
+			name:     "test_goflags_mod_vendor",
+			files:    module,
+			env:      map[string]string{"GOFLAGS": "-mod=vendor"},
+			expected: "inconsistent vendoring",
+		},
+		{
+			name:     "test_args_mod_vendor",
+			files:    module,
+			args:     []string{"-mod=vendor"},
+			expected: "inconsistent vendoring",
+		},
+		{
+			name:     "test_unknown_flag",
+			files:    module,
+			args:     []string{"-unknown"},
+			expected: "flag provided but not defined: -unknown",
+		},
+		{
+			name: "test_broken_go_mod",
+			files: map[string]string{
+				"go.mod": "module example.com/a\n\ngo 1.20\n\nrequire (\n",
+				"a.go":   "package a\n",
+			},
+			expected: "go.mod",
+		},
+		{
+			name:        "test_missing_module",
+			files:       map[string]string{"a.go": "package a\n"},
+			expected:    "does not contain main module",
+			unavailable: true,
+		},
+		{
+			name:        "test_missing_go",
+			files:       module,
+			env:         map[string]string{"PATH": ""},
+			expected:    "executable file not found",
+			unavailable: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Setenv("GOFLAGS", "")
+				t.Setenv("GOWORK", "off")
+				t.Setenv("GOPROXY", "off")
+				for key, value := range tc.env {
+					t.Setenv(key, value)
+				}
+
+				dir := t.TempDir()
+				writeFiles(t, dir, tc.files)
+
+				_, err := listPackages(context.Background(), dir, []string{"./..."}, tc.args...)
+				if err == nil || !strings.Contains(err.Error(), tc.expected) {
+					t.Fatalf("got: %v, want: an error with %q", err, tc.expected)
+				}
+
+				// Only without Go or the module sources the tests are exported without metadata.
+				if errors.Is(err, ErrUnavailable) != tc.unavailable {
+					t.Errorf("got: %v, want unavailable: %v", err, tc.unavailable)
+				}
+			},
+		)
+	}
+}
+
+func TestWorkspacePatterns(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOWORK", "")
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("filepath.EvalSymlinks: %v", err)
+	}
+
+	writeFiles(
+		t, dir, map[string]string{
+			"go.work":           "go 1.20\n\nuse (\n\t./a\n\t./b\n\t../outside\n)\n",
+			"a/go.mod":          "module example.com/a\n\ngo 1.20\n",
+			"b/go.mod":          "module example.com/b\n\ngo 1.20\n",
+			"../outside/go.mod": "module example.com/outside\n\ngo 1.20\n",
+		},
+	)
+
+	got, err := workspacePatterns(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("workspacePatterns: %v", err)
+	}
+
+	sort.Strings(got)
+
+	// The modules of the workspace outside of the directory are not listed.
+	expected := []string{filepath.Join(dir, "a", "..."), filepath.Join(dir, "b", "...")}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// writeFiles writes the files with the slash separated paths relative to the directory.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for pth, body := range files {
+		full := filepath.Join(dir, filepath.FromSlash(pth))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("os.MkdirAll: %v", err)
+		}
+
+		if err := os.WriteFile(full, []byte(body), 0o644); err != nil {
+			t.Fatalf("os.WriteFile: %v", err)
+		}
+	}
+}
+
+// TestImportPathPackages - tests that the packages are listed in the module with the longest matching path.
+// It changes the environment of go list, so it does not run in parallel.
+func TestImportPathPackages(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOPROXY", "off")
+
+	dir := t.TempDir()
+	writeFiles(
+		t, dir, map[string]string{
+			"go.mod":             "module example.com/root\n\ngo 1.20\n",
+			"pkg/pkg.go":         "package pkg\n",
+			"nested/go.mod":      "module example.com/root/nested\n\ngo 1.20\n",
+			"nested/pkg/pkg.go":  "package pkg\n",
+			"nested2/go.mod":     "module example.com/root/nested2\n\ngo 1.20\n",
+			"nested2/pkg/pkg.go": "package pkg\n",
+		},
+	)
+
+	packages, err := ImportPathPackages(
+		context.Background(), fs.New(dir), []string{
+			"example.com/root/pkg",
+			"example.com/root/nested/pkg",
+			"example.com/root/nested2/pkg",
+			"example.com/root/missing",
+			"example.com/other",
+		},
+	)
+	if err != nil {
+		t.Fatalf("ImportPathPackages: %v", err)
+	}
+
+	got := make(map[string]string, len(packages))
+	for _, pkg := range packages {
+		got[pkg.ImportPath] = pkg.Module.Path
+	}
+
+	// The nested module wins over the root module, the packages of no module or not found are dropped.
+	expected := map[string]string{
+		"example.com/root/pkg":         "example.com/root",
+		"example.com/root/nested/pkg":  "example.com/root/nested",
+		"example.com/root/nested2/pkg": "example.com/root/nested2",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		gomod    string
+		expected string
+	}{
+		{
+			name:     "test_module",
+			gomod:    "module example.com/a\n\ngo 1.20\n",
+			expected: "example.com/a",
+		},
+		{
+			name:     "test_comments_and_spaces",
+			gomod:    "// Package a.\n\n  module   example.com/a // the module\n",
+			expected: "example.com/a",
+		},
+		{
+			name:     "test_quoted",
+			gomod:    "module \"example.com/a\"\n",
+			expected: "example.com/a",
+		},
+		{
+			name:     "test_crlf",
+			gomod:    "module example.com/a\r\ngo 1.20\r\n",
+			expected: "example.com/a",
+		},
+		{
+			name:  "test_no_module",
+			gomod: "go 1.20\n\nrequire example.com/module v1.0.0\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				if diff := cmp.Diff(tc.expected, modulePath([]byte(tc.gomod))); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+// TestInWorkspace - tests the detection of the workspace mode by GOWORK and go.work files of the parents.
+// It changes GOWORK, so it does not run in parallel.
+func TestInWorkspace(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		gowork   string
+		dir      string
+		expected bool
+	}{
+		{
+			name:  "test_no_go_work",
+			files: map[string]string{"a/go.mod": "module example.com/a\n"},
+			dir:   "a",
+		},
+		{
+			name:     "test_go_work",
+			files:    map[string]string{"go.work": "go 1.20\n\nuse ./a\n", "a/go.mod": "module example.com/a\n"},
+			dir:      ".",
+			expected: true,
+		},
+		{
+			name:     "test_parent_go_work",
+			files:    map[string]string{"go.work": "go 1.20\n\nuse ./a\n", "a/go.mod": "module example.com/a\n"},
+			dir:      "a/internal",
+			expected: true,
+		},
+		{
+			name:   "test_gowork_off",
+			files:  map[string]string{"go.work": "go 1.20\n\nuse ./a\n", "a/go.mod": "module example.com/a\n"},
+			gowork: "off",
+			dir:    "a",
+		},
+		{
+			name:     "test_gowork_file",
+			files:    map[string]string{"a/go.mod": "module example.com/a\n"},
+			gowork:   filepath.Join(string(filepath.Separator), "src", "go.work"),
+			dir:      "a",
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Setenv("GOWORK", tc.gowork)
+
+				dir := t.TempDir()
+				writeFiles(t, dir, tc.files)
+
+				pth := filepath.Join(dir, filepath.FromSlash(tc.dir))
+				if err := os.MkdirAll(pth, 0o755); err != nil {
+					t.Fatalf("os.MkdirAll: %v", err)
+				}
+
+				if got := inWorkspace(pth); got != tc.expected {
+					t.Errorf("got: %v, want: %v", got, tc.expected)
+				}
+			},
+		)
+	}
+}
+
+func TestFindModules(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(
+		t, dir, map[string]string{
+			"go.mod":                          "module example.com/root\n",
+			"services/api/go.mod":             "module example.com/root/services/api\n",
+			"vendor/example.com/dep/go.mod":   "module example.com/dep\n",
+			"testdata/fixture/go.mod":         "module example.com/fixture\n",
+			"web/node_modules/pkg/go.mod":     "module example.com/pkg\n",
+			".cache/mod/example.com/go.mod":   "module example.com/cached\n",
+			"services/api/testdata/go.mod":    "module example.com/api/fixture\n",
+			"services/api/internal/vendor.go": "package internal\n",
+		},
+	)
+
+	modules, err := findModules(fs.New(dir))
+	if err != nil {
+		t.Fatalf("findModules: %v", err)
+	}
+
+	got := make(map[string]string, len(modules))
+	for _, m := range modules {
+		got[m.path] = m.dir
+	}
+
+	// The modules of vendored, test data, node_modules and hidden directories are not a part of the build.
+	expected := map[string]string{
+		"example.com/root":              dir,
+		"example.com/root/services/api": filepath.Join(dir, "services", "api"),
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
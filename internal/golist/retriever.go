@@ -12,7 +12,7 @@ type FS interface {
 }
 
 type PackageRetriever interface {
-	Retrieve(ctx context.Context) ([]Package, error)
+	Retrieve(ctx context.Context, importPaths ...string) ([]Package, error)
 }
 
 func NewRetriever(fs FS, goBuildTags ...string) PackageRetriever {
@@ -24,7 +24,17 @@ type retriever struct {
 	args []string
 }
 
-func (r *retriever) Retrieve(ctx context.Context) ([]Package, error) {
+// Retrieve lists the packages with the import paths or all packages under the root directory if there are none.
+func (r *retriever) Retrieve(ctx context.Context, importPaths ...string) ([]Package, error) {
+	if len(importPaths) > 0 {
+		packages, err := ImportPathPackages(ctx, r.fs, importPaths, r.args...)
+		if err != nil {
+			return nil, fmt.Errorf("ImportPathPackages: %w", err)
+		}
+
+		return packages, nil
+	}
+
 	packages, err := DirPackages(ctx, r.fs, r.args...)
 	if err != nil {
 		return nil, fmt.Errorf("DirPackages: %w", err)
@@ -0,0 +1,222 @@
+package junit
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robotomize/go-allure/internal/gotest"
+)
+
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05"}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+// Reader reads JUnit XML reports and converts them into go test sets.
+type Reader struct {
+	r io.Reader
+}
+
+// ReadAll decodes testsuites/testsuite documents and returns the test cases as a Set.
+func (r *Reader) ReadAll(ctx context.Context) (gotest.Set, error) {
+	suites, err := r.decode()
+	if err != nil {
+		return gotest.Set{}, err
+	}
+
+	var errs []error
+
+	originLog := bytes.NewBuffer(make([]byte, 0))
+	result := gotest.Set{Tests: make([]gotest.NestedTest, 0)}
+
+	for _, suite := range suites {
+		if err = ctx.Err(); err != nil {
+			return gotest.Set{}, err
+		}
+
+		// Test cases are laid out one after another starting from the suite timestamp.
+		start, parseErr := parseTimestamp(suite.Timestamp)
+		if parseErr != nil {
+			errs = append(errs, fmt.Errorf("testsuite %s timestamp: %w", suite.Name, parseErr))
+		}
+
+		tree := &node{}
+		for _, testCase := range suite.TestCases {
+			tc := r.convert(suite, testCase, start)
+			start = tc.Value.Stop
+
+			tree.insert(tc)
+			originLog.Write(tc.Log)
+		}
+
+		originLog.WriteString(suite.SystemOut.String())
+		originLog.WriteString(suite.SystemErr.String())
+
+		result.Tests = append(result.Tests, tree.nested()...)
+	}
+
+	result.Err = errors.Join(errs...)
+	result.OriginLog = originLog
+
+	return result, nil
+}
+
+// decode reads either a testsuites document or a single testsuite.
+func (r *Reader) decode() ([]TestSuite, error) {
+	decoder := xml.NewDecoder(r.r)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+
+			return nil, fmt.Errorf("xml.Decoder.Token: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "testsuites":
+			var suites TestSuites
+			if err = decoder.DecodeElement(&suites, &start); err != nil {
+				return nil, fmt.Errorf("xml.Decoder.DecodeElement: %w", err)
+			}
+
+			return suites.Suites, nil
+		case "testsuite":
+			var suite TestSuite
+			if err = decoder.DecodeElement(&suite, &start); err != nil {
+				return nil, fmt.Errorf("xml.Decoder.DecodeElement: %w", err)
+			}
+
+			return []TestSuite{suite}, nil
+		default:
+			return nil, fmt.Errorf("unexpected root element %s", start.Name.Local)
+		}
+	}
+}
+
+// convert maps a JUnit test case to a go test, failures become the test log.
+func (r *Reader) convert(suite TestSuite, testCase TestCase, start time.Time) gotest.NestedTest {
+	pkg := testCase.ClassName
+	if pkg == "" {
+		pkg = suite.Name
+	}
+
+	elapsed, _ := strconv.ParseFloat(testCase.Time, 64)
+
+	goTest := gotest.Test{
+		Name:    testCase.Name,
+		Package: pkg,
+		Stage:   gotest.ActionPass,
+		Status:  gotest.ActionPass,
+		Start:   start,
+		Elapsed: time.Duration(elapsed * float64(time.Second)),
+	}
+	goTest.Stop = goTest.Start.Add(goTest.Elapsed)
+
+	log := bytes.NewBuffer(make([]byte, 0))
+	writeResult := func(res *Result) {
+		if res.Message != "" {
+			log.WriteString(res.Message + "\n")
+		}
+
+		if text := strings.TrimSpace(res.Text); text != "" {
+			log.WriteString(text + "\n")
+		}
+	}
+
+	switch {
+	case testCase.Error != nil:
+		goTest.Status = gotest.ActionPanic
+		writeResult(testCase.Error)
+	case testCase.Failure != nil:
+		goTest.Status = gotest.ActionFail
+		writeResult(testCase.Failure)
+	case testCase.Skipped != nil:
+		goTest.Status = gotest.ActionSkip
+		writeResult(testCase.Skipped)
+	default:
+	}
+
+	goTest.Stage = goTest.Status
+
+	log.WriteString(testCase.SystemOut.String())
+	log.WriteString(testCase.SystemErr.String())
+
+	return gotest.NestedTest{Value: goTest, Log: log.Bytes()}
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	if s == "" {
+		return time.Now(), nil
+	}
+
+	var err error
+	for _, layout := range timestampLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Now(), fmt.Errorf("time.Parse: %w", err)
+}
+
+// node groups subtests named Parent/child under their parent test case.
+type node struct {
+	value    gotest.NestedTest
+	children []*node
+	idx      map[string]*node
+}
+
+func (n *node) insert(tc gotest.NestedTest) {
+	parent := n.find(tc.Value.Package, tc.Value.Name)
+
+	child := &node{value: tc}
+	parent.children = append(parent.children, child)
+
+	if n.idx == nil {
+		n.idx = make(map[string]*node)
+	}
+
+	n.idx[tc.Value.Package+"/"+tc.Value.Name] = child
+}
+
+// find returns the closest ancestor already inserted for the given test name or the root node.
+func (n *node) find(pkg, name string) *node {
+	for {
+		pos := strings.LastIndex(name, "/")
+		if pos < 0 {
+			return n
+		}
+
+		name = name[:pos]
+		if parent, ok := n.idx[pkg+"/"+name]; ok {
+			return parent
+		}
+	}
+}
+
+func (n *node) nested() []gotest.NestedTest {
+	tests := make([]gotest.NestedTest, 0, len(n.children))
+	for _, child := range n.children {
+		tc := child.value
+		tc.Children = child.nested()
+		tests = append(tests, tc)
+	}
+
+	return tests
+}
@@ -0,0 +1,103 @@
+package junit
+
+import (
+	"context"
+	_ "embed"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/gotest"
+	"github.com/robotomize/go-allure/internal/slice"
+)
+
+//go:embed testdata/report.xml
+var report string
+
+// TestReader_ReadAll - tests conversion of JUnit XML into go test sets.
+func TestReader_ReadAll(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []gotest.NestedTest
+		err      bool
+	}{
+		{
+			name:  "test_testsuites",
+			input: report,
+			expected: []gotest.NestedTest{
+				{
+					Value: gotest.Test{Name: "TestFilter", Status: gotest.ActionFail},
+					Children: []gotest.NestedTest{
+						{Value: gotest.Test{Name: "TestFilter/test_filtered", Status: gotest.ActionFail}},
+						{Value: gotest.Test{Name: "TestFilter/test_nil_input", Status: gotest.ActionPass}},
+					},
+				},
+				{Value: gotest.Test{Name: "TestMap", Status: gotest.ActionSkip}},
+				{Value: gotest.Test{Name: "TestFlat", Status: gotest.ActionPanic}},
+			},
+		},
+		{
+			name: "test_single_testsuite",
+			input: `<testsuite name="pkg" tests="1">
+				<testcase name="TestOne" time="0.5"></testcase>
+			</testsuite>`,
+			expected: []gotest.NestedTest{
+				{Value: gotest.Test{Name: "TestOne", Status: gotest.ActionPass}},
+			},
+		},
+		{
+			name:  "test_unexpected_root",
+			input: `<report></report>`,
+			err:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				set, err := NewReader(strings.NewReader(tc.input)).ReadAll(context.Background())
+				if (err != nil) != tc.err {
+					t.Fatalf("got: %v, want error: %v", err, tc.err)
+				}
+
+				if diff := cmp.Diff(len(tc.expected), len(set.Tests)); diff != "" {
+					t.Fatalf("mismatch (-want, +got):\n%s", diff)
+				}
+
+				for idx, expected := range tc.expected {
+					got := set.Tests[idx]
+					if diff := cmp.Diff(expected.Value.Name, got.Value.Name); diff != "" {
+						t.Errorf("mismatch (-want, +got):\n%s", diff)
+					}
+
+					if diff := cmp.Diff(expected.Value.Status, got.Value.Status); diff != "" {
+						t.Errorf("mismatch (-want, +got):\n%s", diff)
+					}
+
+					for _, child := range expected.Children {
+						child1, ok := slice.Find(
+							got.Children, func(t gotest.NestedTest) bool {
+								return child.Value.Name == t.Value.Name
+							},
+						)
+						if !ok {
+							t.Errorf("got: %v, want: %v", ok, true)
+							continue
+						}
+
+						if diff := cmp.Diff(child.Value.Status, child1.Value.Status); diff != "" {
+							t.Errorf("mismatch (-want, +got):\n%s", diff)
+						}
+					}
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,387 @@
+package merge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/robotomize/go-allure/internal/allure"
+	"github.com/robotomize/go-allure/internal/exporter"
+)
+
+const (
+	resultSuffix    = "-result.json"
+	environmentFile = "environment.properties"
+	categoriesFile  = "categories.json"
+)
+
+// Result describes what happened to the merged directories.
+type Result struct {
+	Tests       int
+	Duplicates  int
+	Retries     int
+	Attachments int
+}
+
+// Merge combines the allure results directories srcs into the dst directory.
+// Results are deduplicated by UUID, results sharing a historyId are reconciled into retries,
+// colliding attachment sources are renamed and environment/categories files are merged.
+func Merge(ctx context.Context, dst string, srcs ...string) (Result, error) {
+	m := merger{
+		uuids:       make(map[string]struct{}),
+		sources:     make(map[string]struct{}),
+		environment: make(map[string][]string),
+		categories:  make(map[string]struct{}),
+	}
+
+	// Read every source directory in the given order, the first occurrence of a UUID wins.
+	for _, src := range srcs {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+
+		if err := m.readDir(src); err != nil {
+			return Result{}, fmt.Errorf("read %s: %w", src, err)
+		}
+	}
+
+	retries := m.reconcileRetries()
+
+	// Reuse the exporter writer so merged results are written the same way as the exported ones.
+	w := exporter.NewWriter(exporter.WriteToFile(dst))
+	if err := w.WriteReport(ctx, m.tests); err != nil {
+		return Result{}, fmt.Errorf("exporter.NewWriter WriteReport: %w", err)
+	}
+
+	if err := w.WriteAttachments(ctx, m.attachments); err != nil {
+		return Result{}, fmt.Errorf("exporter.NewWriter WriteAttachments: %w", err)
+	}
+
+	if err := m.writeEnvironment(dst); err != nil {
+		return Result{}, fmt.Errorf("write environment: %w", err)
+	}
+
+	if err := m.writeCategories(dst); err != nil {
+		return Result{}, fmt.Errorf("write categories: %w", err)
+	}
+
+	return Result{
+		Tests:       len(m.tests),
+		Duplicates:  m.duplicates,
+		Retries:     retries,
+		Attachments: len(m.attachments),
+	}, nil
+}
+
+type merger struct {
+	tests       []allure.Test
+	attachments []exporter.Attachment
+	duplicates  int
+
+	uuids   map[string]struct{}
+	sources map[string]struct{}
+
+	envKeys     []string
+	environment map[string][]string
+
+	categories    map[string]struct{}
+	categoryItems []json.RawMessage
+}
+
+// readDir reads results, attachments, environment and categories from a single results directory.
+func (m *merger) readDir(src string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("os.ReadDir: %w", err)
+	}
+
+	tests := make([]allure.Test, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		pth := filepath.Join(src, entry.Name())
+
+		switch name := entry.Name(); {
+		case strings.HasSuffix(name, resultSuffix):
+			tc, readErr := readResult(pth)
+			if readErr != nil {
+				return readErr
+			}
+
+			// Skip results that were already merged from another directory.
+			if _, ok := m.uuids[tc.UUID]; ok {
+				m.duplicates++
+				continue
+			}
+
+			m.uuids[tc.UUID] = struct{}{}
+			tests = append(tests, tc)
+		case name == environmentFile:
+			if readErr := m.readEnvironment(pth); readErr != nil {
+				return readErr
+			}
+		case name == categoriesFile:
+			if readErr := m.readCategories(pth); readErr != nil {
+				return readErr
+			}
+		default:
+		}
+	}
+
+	// Copy attachments referenced by the results, renaming sources that collide with already merged ones.
+	renames := make(map[string]string)
+	for idx := range tests {
+		if err = m.rewriteAttachments(src, tests[idx].Attachments, renames); err != nil {
+			return err
+		}
+
+		if err = m.rewriteSteps(src, tests[idx].Steps, renames); err != nil {
+			return err
+		}
+	}
+
+	m.tests = append(m.tests, tests...)
+
+	return nil
+}
+
+func (m *merger) rewriteSteps(src string, steps []allure.Step, renames map[string]string) error {
+	for idx := range steps {
+		if err := m.rewriteAttachments(src, steps[idx].Attachments, renames); err != nil {
+			return err
+		}
+
+		if err := m.rewriteSteps(src, steps[idx].Steps, renames); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *merger) rewriteAttachments(src string, attachments []allure.Attachment, renames map[string]string) error {
+	for idx := range attachments {
+		attachment := &attachments[idx]
+		if source, ok := renames[attachment.Source]; ok {
+			attachment.Source = source
+			continue
+		}
+
+		body, err := os.ReadFile(filepath.Join(src, filepath.Base(attachment.Source)))
+		if err != nil {
+			// Keep the reference as is, allure shows missing attachments as empty.
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return fmt.Errorf("os.ReadFile: %w", err)
+		}
+
+		source := filepath.Base(attachment.Source)
+		if _, ok := m.sources[source]; ok {
+			source = fmt.Sprintf("%s-attachment%s", uuid.New().String(), filepath.Ext(source))
+		}
+
+		m.sources[source] = struct{}{}
+		renames[attachment.Source] = source
+		m.attachments = append(
+			m.attachments, exporter.Attachment{
+				Name:   attachment.Name,
+				Mime:   attachment.Type,
+				Source: source,
+				Body:   body,
+			},
+		)
+
+		attachment.Source = source
+	}
+
+	return nil
+}
+
+// reconcileRetries orders results sharing a historyId by start time so allure shows the latest one
+// and treats the others as retries. The latest result is marked flaky if the attempts disagree.
+func (m *merger) reconcileRetries() int {
+	var retries int
+
+	groups := make(map[string][]int)
+	for idx, tc := range m.tests {
+		if tc.HistoryID == "" {
+			continue
+		}
+
+		groups[tc.HistoryID] = append(groups[tc.HistoryID], idx)
+	}
+
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+
+		retries += len(group) - 1
+
+		sort.Slice(
+			group, func(i, j int) bool {
+				return m.tests[group[i]].Start < m.tests[group[j]].Start
+			},
+		)
+
+		latest := &m.tests[group[len(group)-1]]
+		for _, idx := range group[:len(group)-1] {
+			if m.tests[idx].Status != latest.Status {
+				if latest.StatusDetails == nil {
+					latest.StatusDetails = &allure.StatusDetails{}
+				}
+
+				latest.StatusDetails.Flaky = true
+				break
+			}
+		}
+	}
+
+	return retries
+}
+
+// readEnvironment reads java properties style key=value pairs.
+func (m *merger) readEnvironment(pth string) error {
+	file, err := os.Open(pth)
+	if err != nil {
+		return fmt.Errorf("os.Open: %w", err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			key, value, _ = strings.Cut(line, ":")
+		}
+
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		values, exist := m.environment[key]
+		if !exist {
+			m.envKeys = append(m.envKeys, key)
+		}
+
+		var found bool
+		for _, v := range values {
+			if v == value {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			m.environment[key] = append(values, value)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("bufio.NewScanner.Err: %w", err)
+	}
+
+	return nil
+}
+
+func (m *merger) readCategories(pth string) error {
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		return fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	var items []json.RawMessage
+	if err = json.Unmarshal(b, &items); err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	// Categories are deduplicated by name, the first definition wins.
+	for _, item := range items {
+		var category struct {
+			Name string `json:"name"`
+		}
+		if err = json.Unmarshal(item, &category); err != nil {
+			return fmt.Errorf("json.Unmarshal: %w", err)
+		}
+
+		if _, ok := m.categories[category.Name]; ok {
+			continue
+		}
+
+		m.categories[category.Name] = struct{}{}
+		m.categoryItems = append(m.categoryItems, item)
+	}
+
+	return nil
+}
+
+func (m *merger) writeEnvironment(dst string) error {
+	if len(m.envKeys) == 0 {
+		return nil
+	}
+
+	file, err := os.OpenFile(filepath.Join(dst, environmentFile), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile: %w", err)
+	}
+
+	defer file.Close()
+
+	// Different values of the same key from several directories are joined into one entry.
+	for _, key := range m.envKeys {
+		if _, err = io.WriteString(file, key+"="+strings.Join(m.environment[key], ", ")+"\n"); err != nil {
+			return fmt.Errorf("io.WriteString: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (m *merger) writeCategories(dst string) error {
+	if len(m.categoryItems) == 0 {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(m.categoryItems, "", "    ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+
+	if err = os.WriteFile(filepath.Join(dst, categoriesFile), b, 0o644); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+
+	return nil
+}
+
+func readResult(pth string) (allure.Test, error) {
+	var tc allure.Test
+
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		return tc, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	if err = json.Unmarshal(b, &tc); err != nil {
+		return tc, fmt.Errorf("json.Unmarshal %s: %w", filepath.Base(pth), err)
+	}
+
+	return tc, nil
+}
@@ -0,0 +1,131 @@
+package merge
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+func writeResult(t *testing.T, dir string, tc allure.Test) {
+	t.Helper()
+
+	b, err := json.Marshal(tc)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, tc.UUID+resultSuffix), b, 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+}
+
+func readResults(t *testing.T, dir string) map[string]allure.Test {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("os.ReadDir: %v", err)
+	}
+
+	tests := make(map[string]allure.Test)
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), resultSuffix) {
+			continue
+		}
+
+		tc, readErr := readResult(filepath.Join(dir, entry.Name()))
+		if readErr != nil {
+			t.Fatalf("readResult: %v", readErr)
+		}
+
+		tests[tc.UUID] = tc
+	}
+
+	return tests
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	shard1, shard2, dst := t.TempDir(), t.TempDir(), t.TempDir()
+
+	attachment := []allure.Attachment{{Name: "log", Source: "log-attachment.txt", Type: "text/plain"}}
+
+	writeResult(
+		t, shard1, allure.Test{
+			UUID: "1", HistoryID: "h1", Status: allure.StatusFail, Start: 1, Attachments: attachment,
+		},
+	)
+	writeResult(t, shard1, allure.Test{UUID: "2", HistoryID: "h2", Status: allure.StatusPass, Start: 1})
+	writeResult(
+		t, shard2, allure.Test{
+			UUID: "3", HistoryID: "h1", Status: allure.StatusPass, Start: 2, Attachments: attachment,
+		},
+	)
+	writeResult(t, shard2, allure.Test{UUID: "2", HistoryID: "h2", Status: allure.StatusPass, Start: 1})
+
+	for _, dir := range []string{shard1, shard2} {
+		if err := os.WriteFile(filepath.Join(dir, "log-attachment.txt"), []byte(dir), 0o644); err != nil {
+			t.Fatalf("os.WriteFile: %v", err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(shard1, environmentFile), []byte("os=linux\n"), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(shard2, environmentFile), []byte("os=darwin\n"), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	result, err := Merge(ctx, dst, shard1, shard2)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+
+	expected := Result{Tests: 3, Duplicates: 1, Retries: 1, Attachments: 2}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	tests := readResults(t, dst)
+	if diff := cmp.Diff(3, len(tests)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	if tests["3"].StatusDetails == nil || !tests["3"].StatusDetails.Flaky {
+		t.Errorf("got: %v, want: flaky status details", tests["3"].StatusDetails)
+	}
+
+	source1, source3 := tests["1"].Attachments[0].Source, tests["3"].Attachments[0].Source
+	if source1 == source3 {
+		t.Errorf("got: equal attachment sources %s, want: renamed", source1)
+	}
+
+	body, err := os.ReadFile(filepath.Join(dst, source3))
+	if err != nil {
+		t.Fatalf("os.ReadFile: %v", err)
+	}
+
+	if diff := cmp.Diff(shard2, string(body)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	env, err := os.ReadFile(filepath.Join(dst, environmentFile))
+	if err != nil {
+		t.Fatalf("os.ReadFile: %v", err)
+	}
+
+	if diff := cmp.Diff("os=linux, darwin\n", string(env)); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -6,11 +6,15 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"os"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/robotomize/go-allure/internal/cache"
 	"github.com/robotomize/go-allure/internal/golist"
 	"golang.org/x/sync/errgroup"
 )
@@ -27,14 +31,24 @@ type GoTestMethod struct {
 	TestName     string
 	TestComment  string
 	PackageName  string
+	Dir          string
+	ModuleDir    string
+	ModulePath   string
 	FileName     string
 	TestFileLine int
 	TestFileCol  int
 	GoVersion    string
+	// Source is the code of the function, it is only kept for TestXxx functions.
+	Source string
 }
 
 // ParseTestFiles - parse go test files into slice of GoTestMethod.
 func ParseTestFiles(ctx context.Context, packages []golist.Package) ([]GoTestMethod, error) {
+	return parseTestFiles(ctx, packages, nil)
+}
+
+// parseTestFiles parses go test files, unchanged files are taken from the cache if it is not nil.
+func parseTestFiles(ctx context.Context, packages []golist.Package, c *cache.Cache) ([]GoTestMethod, error) {
 	var goTestFiles []GoTestMethod
 
 	// Use errgroup to limit the number of goroutines.
@@ -83,7 +97,7 @@ OuterLoop:
 
 					// Build the path to the test file and parse it.
 					pth := fmt.Sprintf("%s/%s", pkg.Dir, file)
-					files, err := parse(pth, pkg)
+					files, err := parseCached(c, pth, pkg)
 					if err != nil {
 						return fmt.Errorf("parse: %w", err)
 					}
@@ -116,12 +130,50 @@ OuterLoop:
 	return goTestFiles, nil
 }
 
+type cachedFile struct {
+	Stamps  map[string]cache.Stamp `json:"stamps"`
+	Methods []GoTestMethod         `json:"methods"`
+}
+
+// parseCached parses the file unless it is cached with the same modification time and size.
+func parseCached(c *cache.Cache, pth string, pkg golist.Package) ([]GoTestMethod, error) {
+	if c == nil {
+		return parse(pth, pkg)
+	}
+
+	key := cache.Key("parser", pth, pkg.ImportPath, pkg.Dir, pkg.Module.Path, pkg.Module.Dir, pkg.Module.GoVersion)
+
+	var entry cachedFile
+	if c.Get(key, &entry) && cache.Valid(entry.Stamps) {
+		return entry.Methods, nil
+	}
+
+	stamps, err := cache.Stamps(pth)
+	if err != nil {
+		return nil, fmt.Errorf("cache.Stamps: %w", err)
+	}
+
+	methods, err := parse(pth, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = c.Put(key, cachedFile{Stamps: stamps, Methods: methods})
+
+	return methods, nil
+}
+
 // parse - parse go files into slice of func declarations.
 func parse(pth string, pkg golist.Package) ([]GoTestMethod, error) {
 	fileSet := token.NewFileSet()
 
+	src, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
 	// Use the parser.ParseFile method to parse the test file.
-	f, err := parser.ParseFile(fileSet, pth, nil, parser.ParseComments)
+	f, err := parser.ParseFile(fileSet, pth, src, parser.ParseComments)
 	if err != nil {
 		return nil, fmt.Errorf("Parser.ParseFile: %w", err)
 	}
@@ -159,12 +211,20 @@ func parse(pth string, pkg golist.Package) ([]GoTestMethod, error) {
 						TestName:     x.Name.Name,
 						TestComment:  comment,
 						PackageName:  pkg.ImportPath,
+						Dir:          pkg.Dir,
+						ModuleDir:    pkg.Module.Dir,
+						ModulePath:   pkg.Module.Path,
 						FileName:     fileDetails[0],
 						TestFileLine: lineNum,
 						TestFileCol:  colNum,
 						GoVersion:    pkg.Module.GoVersion,
 					},
 				)
+
+				// Keep the source of test functions only, helpers would bloat the cache and the metadata file.
+				if isTestFunc(x) {
+					files[len(files)-1].Source = string(src[fileSet.Position(x.Pos()).Offset:fileSet.Position(x.End()).Offset])
+				}
 			}
 
 			// Always return true to continue the traversal of the AST.
@@ -174,3 +234,19 @@ func parse(pth string, pkg golist.Package) ([]GoTestMethod, error) {
 
 	return files, nil
 }
+
+// isTestFunc reports whether the declaration is a TestXxx function run by go test.
+func isTestFunc(fn *ast.FuncDecl) bool {
+	if fn.Recv != nil {
+		return false
+	}
+
+	rest, ok := strings.CutPrefix(fn.Name.Name, "Test")
+	if !ok {
+		return false
+	}
+
+	r, _ := utf8.DecodeRuneInString(rest)
+
+	return rest == "" || !unicode.IsLower(r)
+}
@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/cache"
+	"github.com/robotomize/go-allure/internal/golist"
+)
+
+func TestParse_Source(t *testing.T) {
+	t.Parallel()
+
+	src := `package slice
+
+import "testing"
+
+func TestFilter(t *testing.T) {}
+
+func Test(t *testing.T) {}
+
+func Test_map(t *testing.T) {}
+
+func Testable(t *testing.T) {}
+
+func helper(t *testing.T) {}
+
+type suite struct{}
+
+func (s suite) TestMethod(t *testing.T) {}
+`
+
+	pth := filepath.Join(t.TempDir(), "slice_test.go")
+	if err := os.WriteFile(pth, []byte(src), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	files, err := parse(pth, golist.Package{ImportPath: "slice"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	got := make(map[string]string, len(files))
+	for _, file := range files {
+		got[file.TestName] = file.Source
+	}
+
+	// The source is kept for the functions go test runs only.
+	expected := map[string]string{
+		"TestFilter": "func TestFilter(t *testing.T) {}",
+		"Test":       "func Test(t *testing.T) {}",
+		"Test_map":   "func Test_map(t *testing.T) {}",
+		"Testable":   "",
+		"helper":     "",
+		"TestMethod": "",
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestParseCached(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pth := filepath.Join(dir, "a_test.go")
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	// writeTestFile writes the test file with the same modification time, only the size changes the stamp.
+	writeTestFile := func(src string) {
+		if err := os.WriteFile(pth, []byte(src), 0o644); err != nil {
+			t.Fatalf("os.WriteFile: %v", err)
+		}
+
+		if err := os.Chtimes(pth, modTime, modTime); err != nil {
+			t.Fatalf("os.Chtimes: %v", err)
+		}
+	}
+
+	c := cache.New(filepath.Join(dir, "cache"))
+	pkg := golist.Package{ImportPath: "example.com/a", Dir: dir}
+
+	testCases := []struct {
+		name     string
+		src      string
+		pkg      golist.Package
+		expected []string
+	}{
+		{
+			name:     "test_parsed",
+			src:      "package a\n\nfunc TestA(t *testing.T) {}\n",
+			pkg:      pkg,
+			expected: []string{"example.com/a.TestA"},
+		},
+		{
+			name:     "test_cached_with_same_stamp",
+			src:      "package a\n\nfunc TestB(t *testing.T) {}\n",
+			pkg:      pkg,
+			expected: []string{"example.com/a.TestA"},
+		},
+		{
+			name:     "test_parsed_with_other_package",
+			src:      "package a\n\nfunc TestB(t *testing.T) {}\n",
+			pkg:      golist.Package{ImportPath: "example.com/b", Dir: dir},
+			expected: []string{"example.com/b.TestB"},
+		},
+		{
+			name:     "test_parsed_after_change",
+			src:      "package a\n\nfunc TestB(t *testing.T) {}\n\nfunc TestC(t *testing.T) {}\n",
+			pkg:      pkg,
+			expected: []string{"example.com/a.TestB", "example.com/a.TestC"},
+		},
+	}
+
+	// The cases share the file and the cache, so they run in order.
+	for _, tc := range testCases {
+		writeTestFile(tc.src)
+
+		methods, err := parseCached(c, pth, tc.pkg)
+		if err != nil {
+			t.Fatalf("%s: parseCached: %v", tc.name, err)
+		}
+
+		got := make([]string, 0, len(methods))
+		for _, method := range methods {
+			got = append(got, method.PackageName+"."+method.TestName)
+		}
+
+		if diff := cmp.Diff(tc.expected, got); diff != "" {
+			t.Errorf("%s: mismatch (-want, +got):\n%s", tc.name, diff)
+		}
+	}
+}
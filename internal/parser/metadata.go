@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WriteMetadata writes the parsed test methods as JSON to be read later with NewMetadataFile.
+func WriteMetadata(w io.Writer, methods []GoTestMethod) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(methods); err != nil {
+		return fmt.Errorf("json.NewEncoder.Encode: %w", err)
+	}
+
+	return nil
+}
+
+// NewMetadataFile creates a file parser returning the test methods collected before,
+// it works without the sources and the Go toolchain.
+func NewMetadataFile(pth string) *MetadataFile {
+	return &MetadataFile{pth: pth}
+}
+
+type MetadataFile struct {
+	pth string
+}
+
+// ParseFiles reads the test methods of the packages with the given import paths or all of them if there are none.
+func (m *MetadataFile) ParseFiles(_ context.Context, importPaths ...string) ([]GoTestMethod, error) {
+	b, err := os.ReadFile(m.pth)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	var methods []GoTestMethod
+	if err = json.Unmarshal(b, &methods); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	if len(importPaths) == 0 {
+		return methods, nil
+	}
+
+	packages := make(map[string]struct{}, len(importPaths))
+	for _, importPath := range importPaths {
+		packages[importPath] = struct{}{}
+	}
+
+	filtered := make([]GoTestMethod, 0, len(methods))
+	for _, method := range methods {
+		if _, ok := packages[method.PackageName]; ok {
+			filtered = append(filtered, method)
+		}
+	}
+
+	return filtered, nil
+}
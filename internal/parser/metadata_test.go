@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMetadataFile_ParseFiles(t *testing.T) {
+	t.Parallel()
+
+	methods := []GoTestMethod{
+		{
+			TestName: "TestA", TestComment: "TestA tests a", PackageName: "example.com/a", Dir: "/src/a",
+			ModuleDir: "/src", ModulePath: "example.com", FileName: "a_test.go", TestFileLine: 7, TestFileCol: 1,
+			GoVersion: "1.20", Source: "func TestA(t *testing.T) {}",
+		},
+		{TestName: "helper", PackageName: "example.com/a", FileName: "a_test.go", TestFileLine: 9},
+		{TestName: "TestB", PackageName: "example.com/b", FileName: "b_test.go", TestFileLine: 3},
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := WriteMetadata(buf, methods); err != nil {
+		t.Fatalf("WriteMetadata: %v", err)
+	}
+
+	pth := filepath.Join(t.TempDir(), "metadata.json")
+	if err := os.WriteFile(pth, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		importPaths []string
+		expected    []GoTestMethod
+	}{
+		{
+			name:     "test_all_packages",
+			expected: methods,
+		},
+		{
+			name:        "test_tested_packages",
+			importPaths: []string{"example.com/a"},
+			expected:    methods[:2],
+		},
+		{
+			name:        "test_unknown_package",
+			importPaths: []string{"example.com/c"},
+			expected:    []GoTestMethod{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				got, err := NewMetadataFile(pth).ParseFiles(context.Background(), tc.importPaths...)
+				if err != nil {
+					t.Fatalf("ParseFiles: %v", err)
+				}
+
+				if diff := cmp.Diff(tc.expected, got); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+func TestMetadataFile_ParseFilesError(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{"), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	for _, pth := range []string{filepath.Join(dir, "missing.json"), invalid} {
+		if _, err := NewMetadataFile(pth).ParseFiles(context.Background()); err == nil {
+			t.Errorf("%s: got: nil, want: error", filepath.Base(pth))
+		}
+	}
+}
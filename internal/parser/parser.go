@@ -4,31 +4,48 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/robotomize/go-allure/internal/cache"
 	"github.com/robotomize/go-allure/internal/golist"
 )
 
 type PackageRetriever interface {
-	Retrieve(ctx context.Context) ([]golist.Package, error)
+	Retrieve(ctx context.Context, importPaths ...string) ([]golist.Package, error)
 }
 
-func New(packageRetriever PackageRetriever) *Parser {
-	return &Parser{PackageRetriever: packageRetriever}
+type Option func(*Parser)
+
+// WithCache keeps parsed test files in the cache until they change.
+func WithCache(c *cache.Cache) Option {
+	return func(p *Parser) {
+		p.cache = c
+	}
+}
+
+func New(packageRetriever PackageRetriever, opts ...Option) *Parser {
+	p := &Parser{PackageRetriever: packageRetriever}
+	for _, o := range opts {
+		o(p)
+	}
+
+	return p
 }
 
 type Parser struct {
 	PackageRetriever
+	cache *cache.Cache
 }
 
 // ParseFiles retrieves Go packages using the PackageRetriever and parses their test files.
-func (p *Parser) ParseFiles(ctx context.Context) ([]GoTestMethod, error) {
-	packages, err := p.Retrieve(ctx)
+// Only the packages with the given import paths are parsed if there are any.
+func (p *Parser) ParseFiles(ctx context.Context, importPaths ...string) ([]GoTestMethod, error) {
+	packages, err := p.Retrieve(ctx, importPaths...)
 	if err != nil {
 		return nil, fmt.Errorf("PackageRetriever Retrieve: %w", err)
 	}
 
-	files, err := ParseTestFiles(ctx, packages)
+	files, err := parseTestFiles(ctx, packages, p.cache)
 	if err != nil {
-		return nil, fmt.Errorf("ParseTestFiles: %w", err)
+		return nil, fmt.Errorf("parseTestFiles: %w", err)
 	}
 
 	return files, nil
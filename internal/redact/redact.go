@@ -0,0 +1,152 @@
+package redact
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Mask replaces redacted secrets.
+const Mask = "[REDACTED]"
+
+// secretGroup is the name of the subexpression masked instead of the whole match.
+const secretGroup = "secret"
+
+// minValueLen is the shortest masked value, shorter ones would mask unrelated text.
+const minValueLen = 4
+
+// builtinPatterns match AWS keys, bearer tokens, passwords in URLs and JWTs. A bearer token is at least
+// 16 characters long, so ordinary words after "bearer" are kept.
+var builtinPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`),
+	regexp.MustCompile(`\b[Bb]earer\s+(?P<secret>[A-Za-z0-9._~+/-]{16,}=*)`),
+	regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.\-]*://[^\s:/@]+:(?P<secret>[^\s@/]+)@`),
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`),
+}
+
+type Option func(*Redactor)
+
+// WithoutBuiltins disables the built-in patterns.
+func WithoutBuiltins() Option {
+	return func(r *Redactor) {
+		r.noBuiltins = true
+	}
+}
+
+// WithPatterns adds patterns to mask, only the "secret" named group is masked if the pattern has one.
+func WithPatterns(patterns ...*regexp.Regexp) Option {
+	return func(r *Redactor) {
+		r.patterns = append(r.patterns, patterns...)
+	}
+}
+
+// WithValues adds literal values to mask, e.g. the values of environment variables.
+func WithValues(values ...string) Option {
+	return func(r *Redactor) {
+		for _, v := range values {
+			if len(v) >= minValueLen {
+				r.values = append(r.values, []byte(v))
+			}
+		}
+	}
+}
+
+// New creates a redactor with the built-in patterns and the given options.
+func New(opts ...Option) *Redactor {
+	r := &Redactor{}
+	for _, o := range opts {
+		o(r)
+	}
+
+	if !r.noBuiltins {
+		r.patterns = append(append(make([]*regexp.Regexp, 0, len(builtinPatterns)), builtinPatterns...), r.patterns...)
+	}
+
+	// Longer values go first so that a value containing another one is masked as a whole.
+	sort.SliceStable(
+		r.values, func(i, j int) bool {
+			return len(r.values[i]) > len(r.values[j])
+		},
+	)
+
+	return r
+}
+
+// Compile compiles user-supplied patterns.
+func Compile(exprs ...string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("regexp.Compile: %w", err)
+		}
+
+		patterns = append(patterns, re)
+	}
+
+	return patterns, nil
+}
+
+// Redactor masks secrets in logs. A nil Redactor returns input unchanged.
+type Redactor struct {
+	noBuiltins bool
+	patterns   []*regexp.Regexp
+	values     [][]byte
+}
+
+// Redact returns b with masked secrets, b is not modified.
+func (r *Redactor) Redact(b []byte) []byte {
+	if r == nil || len(b) == 0 {
+		return b
+	}
+
+	for _, v := range r.values {
+		b = bytes.ReplaceAll(b, v, []byte(Mask))
+	}
+
+	for _, re := range r.patterns {
+		b = replace(re, b)
+	}
+
+	return b
+}
+
+// String is Redact for strings.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+
+	return string(r.Redact([]byte(s)))
+}
+
+// replace masks the whole match or only its "secret" group.
+func replace(re *regexp.Regexp, b []byte) []byte {
+	group := re.SubexpIndex(secretGroup)
+
+	matches := re.FindAllSubmatchIndex(b, -1)
+	if len(matches) == 0 {
+		return b
+	}
+
+	result := make([]byte, 0, len(b))
+
+	var last int
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if group > 0 {
+			start, end = m[2*group], m[2*group+1]
+			if start < 0 {
+				continue
+			}
+		}
+
+		result = append(result, b[last:start]...)
+		result = append(result, Mask...)
+		last = end
+	}
+
+	return append(result, b[last:]...)
+}
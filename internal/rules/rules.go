@@ -0,0 +1,117 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/robotomize/go-allure/internal/allure"
+	"github.com/robotomize/go-allure/internal/glob"
+)
+
+// Rule adds labels to tests matching all of its non-empty patterns. A pattern is a glob where * does not cross
+// a slash and ** does, or a regular expression enclosed in slashes: /^TestIntegration/.
+type Rule struct {
+	Package string            `yaml:"package,omitempty"`
+	File    string            `yaml:"file,omitempty"`
+	Test    string            `yaml:"test,omitempty"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+// Rules are compiled label rules.
+type Rules []rule
+
+type rule struct {
+	pkg, file, test *regexp.Regexp
+	labels          []allure.Label
+}
+
+// Load reads a YAML file with a list of rules.
+func Load(pth string) ([]Rule, error) {
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	var rules []Rule
+	if err = yaml.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Compile compiles the patterns of the rules.
+func Compile(rules ...Rule) (Rules, error) {
+	compiled := make(Rules, 0, len(rules))
+	for i, r := range rules {
+		if len(r.Labels) == 0 {
+			return nil, fmt.Errorf("rule %d: no labels", i+1)
+		}
+
+		var c rule
+		for _, p := range []struct {
+			pattern string
+			re      **regexp.Regexp
+		}{{r.Package, &c.pkg}, {r.File, &c.file}, {r.Test, &c.test}} {
+			if p.pattern == "" {
+				continue
+			}
+
+			re, err := compilePattern(p.pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+
+			*p.re = re
+		}
+
+		names := make([]string, 0, len(r.Labels))
+		for name := range r.Labels {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+		for _, name := range names {
+			c.labels = append(c.labels, allure.Label{Name: name, Value: r.Labels[name]})
+		}
+
+		compiled = append(compiled, c)
+	}
+
+	return compiled, nil
+}
+
+// Labels returns labels of all rules matching the package import path, the test file name and the test name.
+func (r Rules) Labels(pkg, file, test string) []allure.Label {
+	var labels []allure.Label
+	for _, c := range r {
+		if matchPattern(c.pkg, pkg) && matchPattern(c.file, file) && matchPattern(c.test, test) {
+			labels = append(labels, c.labels...)
+		}
+	}
+
+	return labels
+}
+
+func matchPattern(re *regexp.Regexp, s string) bool {
+	return re == nil || re.MatchString(s)
+}
+
+// compilePattern compiles a /regexp/ as is and translates a glob into an anchored regexp.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("regexp.Compile: %w", err)
+		}
+
+		return re, nil
+	}
+
+	return glob.Compile(pattern), nil
+}
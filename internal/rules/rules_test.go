@@ -0,0 +1,67 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+func TestRules_Labels(t *testing.T) {
+	t.Parallel()
+
+	rules, err := Compile(
+		Rule{Package: "github.com/acme/mono/billing/**", Labels: map[string]string{"owner": "billing", "epic": "Billing"}},
+		Rule{Package: "github.com/acme/mono/*", File: "*_integration_test.go", Labels: map[string]string{"layer": "integration"}},
+		Rule{Test: "/^TestSmoke/", Labels: map[string]string{"severity": "critical"}},
+	)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		pkg      string
+		file     string
+		test     string
+		expected []allure.Label
+	}{
+		{
+			name: "test_double_star",
+			pkg:  "github.com/acme/mono/billing/invoice",
+			file: "invoice_test.go",
+			test: "TestInvoice",
+			expected: []allure.Label{
+				{Name: "epic", Value: "Billing"},
+				{Name: "owner", Value: "billing"},
+			},
+		},
+		{
+			name:     "test_all_patterns_match",
+			pkg:      "github.com/acme/mono/api",
+			file:     "api_integration_test.go",
+			test:     "TestSmokeAPI",
+			expected: []allure.Label{{Name: "layer", Value: "integration"}, {Name: "severity", Value: "critical"}},
+		},
+		{
+			name: "test_single_star_does_not_cross_slash",
+			pkg:  "github.com/acme/mono/api/v2",
+			file: "api_integration_test.go",
+			test: "TestAPI",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				if diff := cmp.Diff(tc.expected, rules.Labels(tc.pkg, tc.file, tc.test)); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+const (
+	resultSuffix      = "-result.json"
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+//go:embed templates/index.html
+var indexPage []byte
+
+type cachedResult struct {
+	modTime time.Time
+	test    allure.Test
+}
+
+func New(dir string) *Server {
+	return &Server{dir: dir, results: make(map[string]cachedResult)}
+}
+
+// Server serves an allure results directory with a lightweight viewer.
+type Server struct {
+	dir string
+
+	mu      sync.Mutex
+	results map[string]cachedResult
+}
+
+// Handler returns the viewer, the results API and the attachments handler.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/api/results", s.handleResults)
+	mux.HandleFunc("/attachments/", s.handleAttachment)
+
+	return mux
+}
+
+// ListenAndServe serves the results directory until the context is done.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("http.Server ListenAndServe: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("http.Server Shutdown: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(indexPage)
+}
+
+// handleResults returns the results filtered by the status and label (name:value) query parameters.
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	tests, err := s.reload()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	labelName, labelValue, _ := strings.Cut(r.URL.Query().Get("label"), ":")
+
+	filtered := make([]allure.Test, 0, len(tests))
+	for _, tc := range tests {
+		if status != "" && tc.Status != status {
+			continue
+		}
+
+		if labelName != "" && !hasLabel(tc, labelName, labelValue) {
+			continue
+		}
+
+		filtered = append(filtered, tc)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(filtered); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) handleAttachment(w http.ResponseWriter, r *http.Request) {
+	// Only files from the results directory itself are served.
+	name := filepath.Base(strings.TrimPrefix(r.URL.Path, "/attachments/"))
+	if name == "." || name == "/" || strings.HasSuffix(name, resultSuffix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.ServeFile(w, r, filepath.Join(s.dir, name))
+}
+
+// reload reads new and changed result files and forgets the removed ones.
+func (s *Server) reload() ([]allure.Test, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadDir: %w", err)
+	}
+
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), resultSuffix) {
+			continue
+		}
+
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			continue
+		}
+
+		seen[entry.Name()] = struct{}{}
+		if cached, ok := s.results[entry.Name()]; ok && cached.modTime.Equal(info.ModTime()) {
+			continue
+		}
+
+		b, readErr := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if readErr != nil {
+			continue
+		}
+
+		// A result may still be written, it is picked up on the next reload.
+		var tc allure.Test
+		if err = json.Unmarshal(b, &tc); err != nil {
+			continue
+		}
+
+		s.results[entry.Name()] = cachedResult{modTime: info.ModTime(), test: tc}
+	}
+
+	tests := make([]allure.Test, 0, len(s.results))
+	for name, cached := range s.results {
+		if _, ok := seen[name]; !ok {
+			delete(s.results, name)
+			continue
+		}
+
+		tests = append(tests, cached.test)
+	}
+
+	sort.Slice(
+		tests, func(i, j int) bool {
+			if tests[i].FullName != tests[j].FullName {
+				return tests[i].FullName < tests[j].FullName
+			}
+
+			return tests[i].Name < tests[j].Name
+		},
+	)
+
+	return tests, nil
+}
+
+func hasLabel(tc allure.Test, name, value string) bool {
+	for _, label := range tc.Labels {
+		if label.Name == name && (value == "" || label.Value == value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/robotomize/go-allure/internal/allure"
+)
+
+func TestServer_Handler(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	writeResult := func(tc allure.Test) {
+		b, err := json.Marshal(tc)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+
+		if err = os.WriteFile(filepath.Join(dir, tc.UUID+resultSuffix), b, 0o644); err != nil {
+			t.Fatalf("os.WriteFile: %v", err)
+		}
+	}
+
+	writeResult(
+		allure.Test{
+			UUID: "1", Name: "TestA", Status: allure.StatusPass, Labels: []allure.Label{{Name: "tag", Value: "UNIT"}},
+		},
+	)
+	writeResult(allure.Test{UUID: "2", Name: "TestB", Status: allure.StatusFail})
+
+	if err := os.WriteFile(filepath.Join(dir, "log-attachment.txt"), []byte("log"), 0o644); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	srv := httptest.NewServer(New(dir).Handler())
+	t.Cleanup(srv.Close)
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "test_all", query: "", expected: []string{"TestA", "TestB"}},
+		{name: "test_status", query: "?status=failed", expected: []string{"TestB"}},
+		{name: "test_label", query: "?label=tag:UNIT", expected: []string{"TestA"}},
+		{name: "test_label_name", query: "?label=owner", expected: []string{}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(
+			tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				resp, err := http.Get(srv.URL + "/api/results" + tc.query)
+				if err != nil {
+					t.Fatalf("http.Get: %v", err)
+				}
+
+				defer resp.Body.Close()
+
+				var tests []allure.Test
+				if err = json.NewDecoder(resp.Body).Decode(&tests); err != nil {
+					t.Fatalf("json.NewDecoder.Decode: %v", err)
+				}
+
+				names := make([]string, 0, len(tests))
+				for _, test := range tests {
+					names = append(names, test.Name)
+				}
+
+				if diff := cmp.Diff(tc.expected, names); diff != "" {
+					t.Errorf("mismatch (-want, +got):\n%s", diff)
+				}
+			},
+		)
+	}
+
+	resp, err := http.Get(srv.URL + "/attachments/log-attachment.txt")
+	if err != nil {
+		t.Fatalf("http.Get: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if diff := cmp.Diff(http.StatusOK, resp.StatusCode); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}